@@ -0,0 +1,9 @@
+-- CreateEnum
+CREATE TYPE "DeploymentMode" AS ENUM ('FULL', 'BUILD_ONLY', 'DEPLOY_ONLY');
+
+-- AlterEnum
+ALTER TYPE "DeploymentStatus" ADD VALUE 'BUILT';
+
+-- AlterTable
+ALTER TABLE "Deployment" ADD COLUMN     "imageDigest" TEXT,
+ADD COLUMN     "mode" "DeploymentMode" NOT NULL DEFAULT 'FULL';
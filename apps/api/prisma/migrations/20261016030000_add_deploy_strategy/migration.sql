@@ -0,0 +1,5 @@
+-- CreateEnum
+CREATE TYPE "DeployStrategy" AS ENUM ('ROLLING', 'BLUE_GREEN');
+
+-- AlterTable
+ALTER TABLE "Project" ADD COLUMN     "deployStrategy" "DeployStrategy" NOT NULL DEFAULT 'ROLLING';
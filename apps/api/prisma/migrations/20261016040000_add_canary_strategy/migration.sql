@@ -0,0 +1,2 @@
+-- AlterEnum
+ALTER TYPE "DeployStrategy" ADD VALUE 'CANARY';
@@ -0,0 +1,9 @@
+-- AlterTable
+ALTER TABLE "Deployment" ADD COLUMN     "currentReplicas" INTEGER,
+ADD COLUMN     "desiredReplicas" INTEGER;
+
+-- AlterTable
+ALTER TABLE "Project" ADD COLUMN     "maxReplicas" INTEGER NOT NULL DEFAULT 1,
+ADD COLUMN     "minReplicas" INTEGER NOT NULL DEFAULT 1,
+ADD COLUMN     "targetCpuPercent" INTEGER,
+ADD COLUMN     "targetMemoryPercent" INTEGER;
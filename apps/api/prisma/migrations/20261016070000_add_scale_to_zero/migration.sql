@@ -0,0 +1,8 @@
+-- AlterEnum
+ALTER TYPE "DeploymentStatus" ADD VALUE 'SLEEPING';
+
+-- AlterTable
+ALTER TABLE "Deployment" ADD COLUMN     "wokenAt" TIMESTAMP(3);
+
+-- AlterTable
+ALTER TABLE "SystemConfig" ADD COLUMN     "scaleToZero" BOOLEAN NOT NULL DEFAULT true;
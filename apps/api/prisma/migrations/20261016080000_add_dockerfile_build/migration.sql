@@ -0,0 +1,3 @@
+-- AlterTable
+ALTER TABLE "Project" ADD COLUMN     "dockerfilePath" TEXT,
+ADD COLUMN     "dockerTarget" TEXT;
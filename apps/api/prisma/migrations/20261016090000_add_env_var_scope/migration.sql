@@ -0,0 +1,13 @@
+-- CreateEnum
+CREATE TYPE "EnvVarScope" AS ENUM ('RUNTIME', 'BUILD', 'BOTH');
+
+-- AlterTable
+ALTER TABLE "EnvironmentVariable" ADD COLUMN     "scope" "EnvVarScope" NOT NULL DEFAULT 'RUNTIME';
+
+-- Existing public framework vars are inlined at build time; Railpack
+-- settings only ever applied to the build
+UPDATE "EnvironmentVariable" SET "scope" = 'BOTH'
+WHERE "key" ~* '^(NEXT_PUBLIC_|VITE_|REACT_APP_|NUXT_PUBLIC_|GATSBY_|PUBLIC_|EXPO_PUBLIC_)';
+
+UPDATE "EnvironmentVariable" SET "scope" = 'BUILD'
+WHERE "key" ~* '^RAILPACK_';
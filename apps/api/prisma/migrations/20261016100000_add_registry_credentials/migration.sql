@@ -0,0 +1,23 @@
+-- CreateEnum
+CREATE TYPE "RegistryType" AS ENUM ('NPM', 'PYPI', 'GO');
+
+-- CreateTable
+CREATE TABLE "RegistryCredential" (
+    "id" TEXT NOT NULL,
+    "type" "RegistryType" NOT NULL,
+    "url" TEXT NOT NULL,
+    "scope" TEXT,
+    "username" TEXT,
+    "token" TEXT NOT NULL,
+    "projectId" TEXT NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "RegistryCredential_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "RegistryCredential_projectId_idx" ON "RegistryCredential"("projectId");
+
+-- AddForeignKey
+ALTER TABLE "RegistryCredential" ADD CONSTRAINT "RegistryCredential_projectId_fkey" FOREIGN KEY ("projectId") REFERENCES "Project"("id") ON DELETE CASCADE ON UPDATE CASCADE;
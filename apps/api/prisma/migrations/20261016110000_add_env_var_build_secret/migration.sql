@@ -0,0 +1,8 @@
+-- AlterTable
+ALTER TABLE "EnvironmentVariable" ADD COLUMN     "buildSecret" BOOLEAN NOT NULL DEFAULT false;
+
+-- Keep existing credentials out of build args; public framework vars are
+-- inlined into client bundles, so they stay build args whatever their name
+UPDATE "EnvironmentVariable" SET "buildSecret" = true
+WHERE "key" ~* '(PASSWORD|SECRET|TOKEN|API_?KEY|PRIVATE_KEY|DATABASE_URL|AUTH|CREDENTIAL|ENCRYPTION_KEY|INDEX_URL)'
+  AND "key" !~* '^(NEXT_PUBLIC_|VITE_|REACT_APP_|NUXT_PUBLIC_|GATSBY_|PUBLIC_|EXPO_PUBLIC_)';
@@ -7,6 +7,7 @@ import { ProjectsModule } from "./projects/projects.module";
 import { DeploymentsModule } from "./deployments/deployments.module";
 import { DomainsModule } from "./domains/domains.module";
 import { EnvVarsModule } from "./envs/env-vars.module";
+import { RegistryCredentialsModule } from "./registry-credentials/registry-credentials.module";
 import { QueuesModule } from "./queues/queues.module";
 import { SettingsModule } from "./settings/settings.module";
 import { HealthModule } from "./health/health.module";
@@ -23,6 +24,7 @@ import { WebhooksModule } from "./webhooks/webhooks.module";
     DeploymentsModule,
     DomainsModule,
     EnvVarsModule,
+    RegistryCredentialsModule,
     QueuesModule,
     SettingsModule,
     HealthModule,
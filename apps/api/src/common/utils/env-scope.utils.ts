@@ -0,0 +1,78 @@
+import { EnvVarScope } from "generated/prisma/enums";
+
+// Prefixes of vars frameworks inline into client bundles at build time,
+// so they're needed by the build as well as at runtime
+const PUBLIC_PREFIXES = [
+  "NEXT_PUBLIC_",
+  "VITE_",
+  "REACT_APP_",
+  "NUXT_PUBLIC_",
+  "GATSBY_",
+  "PUBLIC_",
+  "EXPO_PUBLIC_",
+];
+
+// Key fragments of credentials a Dockerfile build should mount as secrets
+const SECRET_WORDS = [
+  "PASSWORD",
+  "SECRET",
+  "TOKEN",
+  "API_KEY",
+  "APIKEY",
+  "PRIVATE_KEY",
+  "DATABASE_URL",
+  "AUTH",
+  "CREDENTIAL",
+  "ENCRYPTION_KEY",
+  "INDEX_URL",
+];
+
+export interface ScopedEnvVar {
+  key: string;
+  value: string;
+  scope: EnvVarScope;
+  buildSecret: boolean;
+}
+
+export class EnvScopeUtils {
+  // Scope for a var saved without one: public framework vars reach the
+  // build too, Railpack settings only the build, everything else runtime
+  static defaultScope(key: string): EnvVarScope {
+    const upper = key.toUpperCase();
+    if (upper.startsWith("RAILPACK_")) return EnvVarScope.BUILD;
+    if (PUBLIC_PREFIXES.some((prefix) => upper.startsWith(prefix))) {
+      return EnvVarScope.BOTH;
+    }
+    return EnvVarScope.RUNTIME;
+  }
+
+  // Build secret setting for a var saved without one: credentials are
+  // mounted as secrets, public framework vars are always build args since
+  // the client bundle needs their values
+  static defaultBuildSecret(key: string): boolean {
+    const upper = key.toUpperCase();
+    if (PUBLIC_PREFIXES.some((prefix) => upper.startsWith(prefix))) {
+      return false;
+    }
+    return SECRET_WORDS.some((word) => upper.includes(word));
+  }
+
+  // Splits decrypted vars into what the running app gets and what the
+  // build gets; runtime-only vars never reach the image. buildSecrets lists
+  // the build vars a Dockerfile build gets as secrets.
+  static split(vars: ScopedEnvVar[]) {
+    const envVars: Record<string, string> = {};
+    const buildEnvVars: Record<string, string> = {};
+    const buildSecrets: string[] = [];
+
+    for (const v of vars) {
+      if (v.scope !== EnvVarScope.BUILD) envVars[v.key] = v.value;
+      if (v.scope !== EnvVarScope.RUNTIME) {
+        buildEnvVars[v.key] = v.value;
+        if (v.buildSecret) buildSecrets.push(v.key);
+      }
+    }
+
+    return { envVars, buildEnvVars, buildSecrets };
+  }
+}
@@ -67,6 +67,11 @@ export class DeploymentsController {
     return this.deploymentsService.updateStatus(userId, id, status);
   }
 
+  @Post(":id/rollback")
+  rollback(@GetCurrentUserId() userId: string, @Param("id") id: string) {
+    return this.deploymentsService.rollback(userId, id);
+  }
+
   @Post(":id/cancel")
   @HttpCode(200)
   cancel(@GetCurrentUserId() userId: string, @Param("id") id: string) {
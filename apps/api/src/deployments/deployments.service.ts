@@ -6,9 +6,16 @@ import {
 } from "@nestjs/common";
 import { PrismaService } from "../../prisma/prisma.service";
 import { QueuesService } from "../queues/queues.service";
+import { HealthCheckConfig } from "../queues/queue.constants";
 import { EncryptionService } from "src/common/utils/encryption.service";
+import { EnvScopeUtils, ScopedEnvVar } from "src/common/utils/env-scope.utils";
 import { CreateDeploymentDto } from "./dto/create-deployment.dto";
-import { DeploymentStatus, EnvironmentType } from "generated/prisma/enums";
+import {
+  DeploymentStatus,
+  DeploymentTrigger,
+  EnvironmentType,
+  EnvVarScope,
+} from "generated/prisma/enums";
 import { GithubAppService } from "src/git/git.service";
 
 @Injectable()
@@ -25,6 +32,16 @@ export class DeploymentsService {
   // --- 1. TRIGGER DEPLOYMENT ---
   async create(userId: string, dto: CreateDeploymentDto) {
     const { projectId } = dto;
+    const mode = dto.mode ?? "FULL";
+
+    // Deploy-only promotes an existing image: no git, no build
+    if (mode === "DEPLOY_ONLY") {
+      return this.deployExistingImage(userId, dto.sourceDeploymentId!, {
+        projectId,
+        trigger: "MANUAL",
+        allowedStatuses: ["BUILT", "SUPERSEDED", "EXPIRED"],
+      });
+    }
 
     // A. Verify Project Ownership & Get Config
     const project = await this.prisma.project.findFirst({
@@ -49,18 +66,10 @@ export class DeploymentsService {
       throw new BadRequestException("No linked GitHub accounts found.");
     }
 
-    // Check if the latest deployment already in progress/queued
-    const latestDeployment = await this.prisma.deployment.findFirst({
-      where: { projectId },
-      orderBy: { startedAt: "desc" },
-    });
-    if (
-      latestDeployment &&
-      ["QUEUED", "BUILDING", "DEPLOYING"].includes(latestDeployment.status)
-    ) {
-      throw new BadRequestException(
-        "A deployment is already in progress for this project.",
-      );
+    // Check if the latest deployment already in progress/queued.
+    // Build-only jobs don't touch traffic, so they may run alongside one.
+    if (mode !== "BUILD_ONLY") {
+      await this.assertNoDeploymentInProgress(projectId);
     }
 
     // Try to match owner, otherwise pick the first (likely correct for single-user scenarios)
@@ -93,6 +102,7 @@ export class DeploymentsService {
           projectId,
           initiatorId: userId,
           status: "QUEUED",
+          mode,
           deploymentUrl,
           // Metadata Snapshot
           branch: project.gitBranch,
@@ -103,13 +113,16 @@ export class DeploymentsService {
         },
       });
 
-      await tx.project.update({
-        where: { id: projectId },
-        data: {
-          configChanged: false,
-          updatedAt: new Date(),
-        },
-      });
+      // A prebuild doesn't apply the current config to anything live
+      if (mode !== "BUILD_ONLY") {
+        await tx.project.update({
+          where: { id: projectId },
+          data: {
+            configChanged: false,
+            updatedAt: new Date(),
+          },
+        });
+      }
 
       return deployment;
     });
@@ -117,27 +130,23 @@ export class DeploymentsService {
     // C. Prepare Environment Variables
     // We must decrypt them so the Build Worker can actually use them
     const domains = project.domains.map((d) => d.name);
-    const envVars: Record<string, string> = {};
-    const projectEnvVars = project.envVars as {
-      key: string;
-      value: string;
-      targets: EnvironmentType[];
-    }[];
-
-    for (const v of projectEnvVars) {
-      if (!v.targets.includes("PRODUCTION")) continue;
-
-      try {
-        envVars[v.key] = this.encryptionService.decrypt(v.value);
-      } catch {
-        this.logger.warn(
-          `Failed to decrypt var ${v.key} for project ${projectId}`,
-        );
-      }
-    }
+    const { envVars, buildEnvVars, buildSecrets } = this.decryptEnvVars(
+      project.id,
+      project.envVars,
+      "PRODUCTION",
+    );
 
     // D. Push Job to Redis
     await this.queueService.addBuildJob({
+      mode: mode === "BUILD_ONLY" ? "build-only" : "full",
+      strategy: project.deployStrategy,
+      healthCheck: (project.healthCheck as HealthCheckConfig | null) ?? undefined,
+      scaling: {
+        minReplicas: project.minReplicas,
+        maxReplicas: project.maxReplicas,
+        targetCpuPercent: project.targetCpuPercent ?? undefined,
+        targetMemoryPercent: project.targetMemoryPercent ?? undefined,
+      },
       deploymentId: deployment.id,
       projectId: project.id,
       projectName: project.name,
@@ -153,14 +162,18 @@ export class DeploymentsService {
         buildCommand: project.buildCommand || undefined,
         runCommand: project.runCommand || undefined,
         outputDir: project.outputDirectory || undefined,
+        dockerfile: project.dockerfilePath || undefined,
+        dockerTarget: project.dockerTarget || undefined,
       },
       domains,
-      envVars: {
-        ...envVars,
+      envVars,
+      buildEnvVars: {
+        ...buildEnvVars,
         ...(project.pythonVersion
           ? { RAILPACK_PYTHON_VERSION: String(project.pythonVersion) }
           : {}),
       },
+      buildSecrets,
     });
 
     this.logger.log(
@@ -270,6 +283,132 @@ export class DeploymentsService {
     });
   }
 
+  // --- ROLLBACK (redeploy a previous image without rebuilding) ---
+  async rollback(userId: string, deploymentId: string) {
+    return this.deployExistingImage(userId, deploymentId, {
+      trigger: "ROLLBACK",
+      // Only images that were actually served can be rolled back to
+      allowedStatuses: ["SUPERSEDED", "EXPIRED"],
+    });
+  }
+
+  // Queues a deploy-only job for the image another deployment built
+  private async deployExistingImage(
+    userId: string,
+    sourceDeploymentId: string,
+    options: {
+      projectId?: string;
+      trigger: DeploymentTrigger;
+      allowedStatuses: DeploymentStatus[];
+    },
+  ) {
+    const source = await this.prisma.deployment.findFirst({
+      where: {
+        id: sourceDeploymentId,
+        project: { userId },
+        ...(options.projectId ? { projectId: options.projectId } : {}),
+      },
+      include: {
+        project: {
+          include: {
+            envVars: true,
+            domains: { orderBy: { createdAt: "asc" } },
+          },
+        },
+      },
+    });
+    if (!source) throw new NotFoundException("Deployment not found");
+
+    const { project } = source;
+
+    if (!source.containerImage) {
+      throw new BadRequestException("Deployment has no built image to deploy.");
+    }
+
+    if (!options.allowedStatuses.includes(source.status)) {
+      throw new BadRequestException(
+        `Cannot deploy the image of a deployment in status: ${source.status}`,
+      );
+    }
+
+    await this.assertNoDeploymentInProgress(project.id);
+
+    const liveDeployment = await this.prisma.deployment.findFirst({
+      where: {
+        projectId: project.id,
+        status: { in: ["READY", "SLEEPING"] },
+        environment: "PRODUCTION",
+      },
+      orderBy: { startedAt: "desc" },
+    });
+
+    const deployment = await this.prisma.deployment.create({
+      data: {
+        projectId: project.id,
+        initiatorId: userId,
+        status: "QUEUED",
+        trigger: options.trigger,
+        mode: "DEPLOY_ONLY",
+        deploymentUrl: project.domains[0]?.name,
+        containerImage: source.containerImage,
+        imageDigest: source.imageDigest,
+        branch: source.branch,
+        commitHash: source.commitHash,
+        commitMessage: source.commitMessage,
+        commitAuthor: source.commitAuthor,
+        deploymentRegion: source.deploymentRegion,
+      },
+    });
+
+    await this.queueService.addBuildJob({
+      ...(options.trigger === "ROLLBACK" ? { type: "rollback" as const } : {}),
+      mode: "deploy-only",
+      strategy: project.deployStrategy,
+      healthCheck: (project.healthCheck as HealthCheckConfig | null) ?? undefined,
+      scaling: {
+        minReplicas: project.minReplicas,
+        maxReplicas: project.maxReplicas,
+        targetCpuPercent: project.targetCpuPercent ?? undefined,
+        targetMemoryPercent: project.targetMemoryPercent ?? undefined,
+      },
+      deploymentId: deployment.id,
+      projectId: project.id,
+      projectName: project.name,
+      commitHash: source.commitHash,
+      containerImage: source.containerImage,
+      imageDigest: source.imageDigest || undefined,
+      sourceDeploymentId: source.id,
+      buildConfig: { framework: project.framework },
+      domains: project.domains.map((d) => d.name),
+      envVars: this.decryptEnvVars(project.id, project.envVars, "PRODUCTION").envVars,
+      previousDeploymentId: liveDeployment?.id,
+    });
+
+    this.logger.log(
+      `Deployment ${deployment.id} (${options.trigger}) queued for project ${project.name} ` +
+        `with image from ${source.id}`,
+    );
+
+    return deployment;
+  }
+
+  private async assertNoDeploymentInProgress(projectId: string) {
+    const inProgress = await this.prisma.deployment.findFirst({
+      where: {
+        projectId,
+        status: { in: ["QUEUED", "BUILDING", "DEPLOYING"] },
+        mode: { not: "BUILD_ONLY" },
+        // Previews run on their own resources
+        environment: "PRODUCTION",
+      },
+    });
+    if (inProgress) {
+      throw new BadRequestException(
+        "A deployment is already in progress for this project.",
+      );
+    }
+  }
+
   async cancel(userId: string, deploymentId: string) {
     const deployment = await this.prisma.deployment.findFirst({
       where: { id: deploymentId, project: { userId } },
@@ -310,4 +449,40 @@ export class DeploymentsService {
 
     return updated;
   }
+
+  // Decrypt the project's env vars that target the given environment,
+  // split into runtime and build vars
+  private decryptEnvVars(
+    projectId: string,
+    vars: unknown,
+    environment: EnvironmentType,
+  ) {
+    const decrypted: ScopedEnvVar[] = [];
+    const projectEnvVars = vars as {
+      key: string;
+      value: string;
+      targets: EnvironmentType[];
+      scope: EnvVarScope;
+      buildSecret: boolean;
+    }[];
+
+    for (const v of projectEnvVars) {
+      if (!v.targets.includes(environment)) continue;
+
+      try {
+        decrypted.push({
+          key: v.key,
+          value: this.encryptionService.decrypt(v.value),
+          scope: v.scope,
+          buildSecret: v.buildSecret,
+        });
+      } catch {
+        this.logger.warn(
+          `Failed to decrypt var ${v.key} for project ${projectId}`,
+        );
+      }
+    }
+
+    return EnvScopeUtils.split(decrypted);
+  }
 }
@@ -1,4 +1,5 @@
-import { IsString, IsOptional } from 'class-validator';
+import { IsString, IsOptional, IsEnum, ValidateIf } from 'class-validator';
+import { DeploymentMode } from 'generated/prisma/enums';
 
 export class CreateDeploymentDto {
   @IsString()
@@ -7,4 +8,14 @@ export class CreateDeploymentDto {
   @IsString()
   @IsOptional()
   branch?: string; // Optional: Deploy a specific branch manually
+
+  // BUILD_ONLY prebuilds an image without deploying it;
+  // DEPLOY_ONLY promotes the image of sourceDeploymentId
+  @IsEnum(DeploymentMode)
+  @IsOptional()
+  mode?: DeploymentMode;
+
+  @ValidateIf((dto: CreateDeploymentDto) => dto.mode === DeploymentMode.DEPLOY_ONLY)
+  @IsString()
+  sourceDeploymentId?: string;
 }
\ No newline at end of file
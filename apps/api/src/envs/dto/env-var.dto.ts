@@ -1,6 +1,6 @@
 import { Type } from 'class-transformer';
-import { IsString, IsNotEmpty, IsEnum, IsArray, ValidateNested } from 'class-validator';
-import { EnvironmentType } from 'generated/prisma/enums';
+import { IsString, IsNotEmpty, IsEnum, IsArray, IsBoolean, IsOptional, ValidateNested } from 'class-validator';
+import { EnvironmentType, EnvVarScope } from 'generated/prisma/enums';
 
 export class EnvVarItemDto {
   @IsString()
@@ -13,6 +13,16 @@ export class EnvVarItemDto {
   @IsArray()
   @IsEnum(EnvironmentType, { each: true })
   targets: EnvironmentType[];
+
+  // Defaults by key when omitted (see EnvScopeUtils.defaultScope)
+  @IsOptional()
+  @IsEnum(EnvVarScope)
+  scope?: EnvVarScope;
+
+  // Defaults by key when omitted (see EnvScopeUtils.defaultBuildSecret)
+  @IsOptional()
+  @IsBoolean()
+  buildSecret?: boolean;
 }
 
 export class SaveEnvVarsDto {
@@ -2,6 +2,7 @@ import { Injectable, NotFoundException } from '@nestjs/common';
 import { PrismaService } from '../../prisma/prisma.service';
 import { SaveEnvVarsDto } from './dto/env-var.dto';
 import { EncryptionService } from 'src/common/utils/encryption.service';
+import { EnvScopeUtils } from 'src/common/utils/env-scope.utils';
 
 @Injectable()
 export class EnvVarsService {
@@ -46,7 +47,9 @@ export class EnvVarsService {
             projectId,
             key: v.key,
             value: this.encryption.encrypt(v.value), 
-            targets: v.targets
+            targets: v.targets,
+            scope: v.scope ?? EnvScopeUtils.defaultScope(v.key),
+            buildSecret: v.buildSecret ?? EnvScopeUtils.defaultBuildSecret(v.key),
           }))
         });
       }
@@ -1,4 +1,4 @@
-import { IsEnum, IsNotEmpty, IsOptional, IsString } from 'class-validator';
+import { IsEnum, IsInt, IsNotEmpty, IsOptional, IsString } from 'class-validator';
 import { DeploymentStatus } from 'generated/prisma/enums';
 
 export class DeploymentNotificationDto {
@@ -21,4 +21,8 @@ export class DeploymentNotificationDto {
   @IsOptional()
   @IsString()
   deploymentUrl?: string;
+
+  @IsOptional()
+  @IsInt()
+  duration?: number;
 }
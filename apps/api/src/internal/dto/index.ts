@@ -3,3 +3,4 @@ export * from './create-logs.dto';
 export * from './update-project-status.dto';
 export * from './update-domain-status.dto';
 export * from './deployment-notification.dto';
+export * from './report-replicas.dto';
@@ -0,0 +1,23 @@
+import { IsArray, IsInt, IsNotEmpty, IsString, Min, ValidateNested } from 'class-validator';
+import { Type } from 'class-transformer';
+
+export class ReplicaCountDto {
+  @IsString()
+  @IsNotEmpty()
+  deploymentId: string;
+
+  @IsInt()
+  @Min(0)
+  currentReplicas: number;
+
+  @IsInt()
+  @Min(0)
+  desiredReplicas: number;
+}
+
+export class ReportReplicasDto {
+  @IsArray()
+  @ValidateNested({ each: true })
+  @Type(() => ReplicaCountDto)
+  counts: ReplicaCountDto[];
+}
@@ -1,6 +1,73 @@
-import { IsEnum, IsNotEmpty, IsOptional, IsString } from 'class-validator';
+import { Type } from 'class-transformer';
+import {
+  IsArray,
+  IsEnum,
+  IsIn,
+  IsInt,
+  IsNotEmpty,
+  IsOptional,
+  IsString,
+  ValidateNested,
+} from 'class-validator';
 import { DeploymentStatus } from 'generated/prisma/enums';
 
+export const FAILURE_PHASES = ['SETUP', 'CLONE', 'BUILD', 'DEPLOY', 'HEALTH'] as const;
+
+export class DeploymentFailureDto {
+  @IsIn(FAILURE_PHASES)
+  phase: (typeof FAILURE_PHASES)[number];
+
+  @IsString()
+  @IsNotEmpty()
+  code: string;
+
+  @IsString()
+  message: string;
+
+  @IsOptional()
+  @IsInt()
+  exitCode?: number;
+
+  @IsOptional()
+  @IsArray()
+  @IsString({ each: true })
+  logTail?: string[];
+}
+
+// Per-phase durations in milliseconds; phases that never ran are omitted
+export class DeploymentTimingsDto {
+  @IsOptional()
+  @IsInt()
+  queueWaitMs?: number;
+
+  @IsOptional()
+  @IsInt()
+  tokenFetchMs?: number;
+
+  @IsOptional()
+  @IsInt()
+  cloneMs?: number;
+
+  @IsOptional()
+  @IsInt()
+  prepareMs?: number;
+
+  @IsOptional()
+  @IsInt()
+  buildMs?: number;
+
+  @IsOptional()
+  @IsInt()
+  applyMs?: number;
+
+  @IsOptional()
+  @IsInt()
+  readinessMs?: number;
+
+  @IsInt()
+  totalMs: number;
+}
+
 export class UpdateDeploymentStatusDto {
   @IsEnum(DeploymentStatus)
   @IsNotEmpty()
@@ -10,7 +77,21 @@ export class UpdateDeploymentStatusDto {
   @IsString()
   containerImage?: string;
 
+  @IsOptional()
+  @IsString()
+  imageDigest?: string;
+
   @IsOptional()
   @IsString()
   deploymentUrl?: string;
+
+  @IsOptional()
+  @ValidateNested()
+  @Type(() => DeploymentFailureDto)
+  failure?: DeploymentFailureDto;
+
+  @IsOptional()
+  @ValidateNested()
+  @Type(() => DeploymentTimingsDto)
+  timings?: DeploymentTimingsDto;
 }
@@ -16,7 +16,8 @@ import {
   CreateLogsDto, 
   UpdateProjectStatusDto, 
   UpdateDomainStatusDto,
-  DeploymentNotificationDto 
+  DeploymentNotificationDto,
+  ReportReplicasDto
 } from './dto';
 import { LogSource } from 'generated/prisma/enums';
 
@@ -59,11 +60,21 @@ export class InternalController {
     return this.internalService.getSettingsByProject(projectId);
   }
 
+  @Get('projects/:id/registry-credentials')
+  getRegistryCredentials(@Param('id') projectId: string) {
+    return this.internalService.getRegistryCredentials(projectId);
+  }
+
   @Get('deployments/expired')
   getExpiredDeployments() {
     return this.internalService.getExpiredDeployments();
   }
 
+  @Post('deployments/replicas')
+  reportReplicas(@Body() dto: ReportReplicasDto) {
+    return this.internalService.reportReplicas(dto);
+  }
+
   @Post('logs/cleanup')
   cleanupLogs() {
     return this.internalService.cleanupLogs();
@@ -10,6 +10,7 @@ import {
   UpdateProjectStatusDto,
   UpdateDomainStatusDto,
   DeploymentNotificationDto,
+  ReportReplicasDto,
 } from "./dto";
 import { DeploymentStatus, LogSource } from "generated/prisma/enums";
 
@@ -30,6 +31,19 @@ export class InternalService {
     });
     if (!deployment) throw new NotFoundException("Deployment not found");
 
+    // Waking from scale-to-zero only restarts the idle timer; the
+    // deployment finished when it first became ready
+    if (deployment.status === "SLEEPING" && dto.status === "READY") {
+      const woken = await this.prisma.deployment.update({
+        where: { id },
+        data: { status: dto.status, wokenAt: new Date() },
+        include: { project: { select: { name: true } } },
+      });
+
+      this.logger.log(`Deployment ${id} woke up`);
+      return woken;
+    }
+
     const updateData: Record<string, unknown> = { status: dto.status };
 
     // Set containerImage if provided
@@ -37,20 +51,35 @@ export class InternalService {
       updateData.containerImage = dto.containerImage;
     }
 
+    // Set imageDigest if provided
+    if (dto.imageDigest) {
+      updateData.imageDigest = dto.imageDigest;
+    }
+
     // Set deploymentUrl if provided
     if (dto.deploymentUrl) {
       updateData.deploymentUrl = dto.deploymentUrl;
     }
 
+    // Persist the structured failure reason sent with FAILED
+    if (dto.failure) {
+      updateData.failure = { ...dto.failure };
+    }
+
+    // Persist the per-phase timing breakdown sent on READY/FAILED
+    if (dto.timings) {
+      updateData.timings = { ...dto.timings };
+    }
+
     // Set finishedAt and calculate duration for terminal states
-    if (["READY", "FAILED", "CANCELED"].includes(dto.status)) {
+    if (["READY", "BUILT", "FAILED", "CANCELED"].includes(dto.status)) {
       updateData.finishedAt = new Date();
       updateData.duration = Math.floor(
         (Date.now() - deployment.startedAt.getTime()) / 1000,
       );
     }
 
-    if (dto.status === "READY") {
+    if (dto.status === "READY" || dto.status === "BUILT") {
       updateData.isSuccess = true;
     }
 
@@ -110,6 +139,23 @@ export class InternalService {
     };
   }
 
+  // Decrypted for the worker, which passes them to builds as secrets
+  async getRegistryCredentials(projectId: string) {
+    const credentials = await this.prisma.registryCredential.findMany({
+      where: { projectId },
+      select: { type: true, url: true, scope: true, username: true, token: true },
+      orderBy: { createdAt: "asc" },
+    });
+
+    return credentials.map((c) => ({
+      type: c.type,
+      url: c.url,
+      scope: c.scope ?? undefined,
+      username: c.username ?? undefined,
+      token: this.encryption.decrypt(c.token),
+    }));
+  }
+
   async getSettingsByProject(projectId: string) {
     const project = await this.prisma.project.findUnique({
       where: { id: projectId },
@@ -129,6 +175,7 @@ export class InternalService {
         logRetentionDays: 1,
         turboMode: false,
         globalTTLMinutes: 5,
+        scaleToZero: true,
         autoDeploy: true,
         emailDeployFailed: true,
         emailDeploySuccess: true,
@@ -154,6 +201,7 @@ export class InternalService {
       logRetentionDays: config.logRetentionDays,
       turboMode: config.turboMode,
       globalTTLMinutes: config.globalTTLMinutes,
+      scaleToZero: config.scaleToZero,
       autoDeploy: config.autoDeploy,
       emailDeployFailed: config.emailDeployFailed,
       emailDeploySuccess: config.emailDeploySuccess,
@@ -164,7 +212,7 @@ export class InternalService {
   async getExpiredDeployments() {
     // Get all configs with their TTL settings
     const configs = await this.prisma.systemConfig.findMany({
-      select: { userId: true, globalTTLMinutes: true },
+      select: { userId: true, globalTTLMinutes: true, scaleToZero: true },
     });
 
     const expiredDeployments: Array<{
@@ -173,8 +221,11 @@ export class InternalService {
       projectName: string;
       containerImage: string | null;
       deploymentUrl: string | null;
+      environment: string;
+      branch: string;
       startedAt: Date;
       ttlMinutes: number;
+      scaleToZero: boolean;
     }> = [];
 
     for (const config of configs) {
@@ -185,7 +236,11 @@ export class InternalService {
         where: {
           project: { userId: config.userId },
           status: "READY",
-          startedAt: { lt: expiryThreshold },
+          // Idle since it started, or since a request last woke it
+          OR: [
+            { wokenAt: null, startedAt: { lt: expiryThreshold } },
+            { wokenAt: { lt: expiryThreshold } },
+          ],
         },
         include: { project: { select: { name: true } } },
       });
@@ -197,8 +252,12 @@ export class InternalService {
           projectName: d.project.name,
           containerImage: d.containerImage,
           deploymentUrl: d.deploymentUrl,
+          // The worker derives preview resource names from these
+          environment: d.environment,
+          branch: d.branch,
           startedAt: d.startedAt,
           ttlMinutes: config.globalTTLMinutes,
+          scaleToZero: config.scaleToZero,
         });
       }
     }
@@ -275,6 +334,23 @@ export class InternalService {
     return updated;
   }
 
+  // Counts arrive only when they change; deployments that are gone are skipped
+  async reportReplicas(dto: ReportReplicasDto) {
+    const results = await this.prisma.$transaction(
+      dto.counts.map((count) =>
+        this.prisma.deployment.updateMany({
+          where: { id: count.deploymentId },
+          data: {
+            currentReplicas: count.currentReplicas,
+            desiredReplicas: count.desiredReplicas,
+          },
+        }),
+      ),
+    );
+
+    return { updated: results.reduce((sum, r) => sum + r.count, 0) };
+  }
+
   async updateDomainStatus(id: string, dto: UpdateDomainStatusDto) {
     const domain = await this.prisma.domain.findUnique({ where: { id } });
     if (!domain) throw new NotFoundException("Domain not found");
@@ -440,12 +516,14 @@ export class InternalService {
     const emojis: Record<DeploymentStatus, string> = {
       QUEUED: "⏳",
       BUILDING: "🔨",
+      BUILT: "📦",
       DEPLOYING: "🚀",
       READY: "✅",
       FAILED: "❌",
       CANCELED: "🚫",
       EXPIRED: "🧹",
-      SUPERSEDED: "♻️"
+      SUPERSEDED: "♻️",
+      SLEEPING: "💤",
     };
     return emojis[status] || "📋";
   }
@@ -454,12 +532,14 @@ export class InternalService {
     const colors: Record<DeploymentStatus, string> = {
       QUEUED: "#808080",
       BUILDING: "#FFA500",
+      BUILT: "#4682B4",
       DEPLOYING: "#0000FF",
       READY: "#00FF00",
       FAILED: "#FF0000",
       CANCELED: "#808080",
       EXPIRED:  "#A52A2A",
       SUPERSEDED: "#ff7b00",
+      SLEEPING: "#6A5ACD",
     };
     return colors[status] || "#808080";
   }
@@ -1,4 +1,7 @@
-import { IsString, IsNotEmpty, IsOptional, Matches } from 'class-validator';
+import { IsString, IsNotEmpty, IsOptional, IsEnum, IsInt, Matches, Max, MaxLength, Min, ValidateNested } from 'class-validator';
+import { Type } from 'class-transformer';
+import { DeployStrategy } from 'generated/prisma/enums';
+import { HealthCheckDto } from './health-check.dto';
 
 export class BaseProjectDto {
   @IsString()
@@ -62,4 +65,50 @@ export class BaseProjectDto {
   @IsString()
   @IsOptional()
   pythonVersion?: string;
+
+  // Relative to rootDirectory; a root-level Dockerfile is picked up without it
+  @IsString()
+  @IsOptional()
+  @MaxLength(255)
+  dockerfilePath?: string;
+
+  @IsString()
+  @IsOptional()
+  @MaxLength(128)
+  dockerTarget?: string;
+
+  // Deployment Config
+  @IsOptional()
+  @IsEnum(DeployStrategy)
+  deployStrategy?: DeployStrategy;
+
+  @IsOptional()
+  @ValidateNested()
+  @Type(() => HealthCheckDto)
+  healthCheck?: HealthCheckDto;
+
+  // Autoscaling: a max above min adds an autoscaler; targets are % of requests
+  @IsOptional()
+  @IsInt()
+  @Min(1)
+  @Max(10)
+  minReplicas?: number;
+
+  @IsOptional()
+  @IsInt()
+  @Min(1)
+  @Max(10)
+  maxReplicas?: number;
+
+  @IsOptional()
+  @IsInt()
+  @Min(10)
+  @Max(100)
+  targetCpuPercent?: number;
+
+  @IsOptional()
+  @IsInt()
+  @Min(10)
+  @Max(100)
+  targetMemoryPercent?: number;
 }
@@ -1,5 +1,6 @@
-import { IsString, IsNotEmpty, IsOptional, IsArray, ValidateNested } from 'class-validator';
+import { IsString, IsNotEmpty, IsOptional, IsArray, IsBoolean, IsEnum, ValidateNested } from 'class-validator';
 import { Type } from 'class-transformer';
+import { EnvVarScope } from 'generated/prisma/enums';
 import { BaseProjectDto } from './base-project.dto';
 
 class EnvVarDto {
@@ -9,6 +10,14 @@ class EnvVarDto {
 
   @IsString()
   value: string;
+
+  @IsOptional()
+  @IsEnum(EnvVarScope)
+  scope?: EnvVarScope;
+
+  @IsOptional()
+  @IsBoolean()
+  buildSecret?: boolean;
 }
 
 export class CreateProjectDto extends BaseProjectDto {
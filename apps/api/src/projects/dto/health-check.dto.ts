@@ -0,0 +1,48 @@
+import { IsInt, IsOptional, IsString, Matches, Max, Min } from 'class-validator';
+
+// Probe configuration sent to the worker with every deploy. Without a path
+// the app is only checked for an open port.
+export class HealthCheckDto {
+  @IsOptional()
+  @IsString()
+  @Matches(/^\/\S*$/, { message: 'Health check path must start with /' })
+  path?: string;
+
+  // Any 2xx/3xx is accepted when omitted
+  @IsOptional()
+  @IsInt()
+  @Min(100)
+  @Max(599)
+  expectedStatus?: number;
+
+  @IsOptional()
+  @IsInt()
+  @Min(0)
+  @Max(600)
+  initialDelaySeconds?: number;
+
+  @IsOptional()
+  @IsInt()
+  @Min(1)
+  @Max(300)
+  periodSeconds?: number;
+
+  @IsOptional()
+  @IsInt()
+  @Min(1)
+  @Max(60)
+  timeoutSeconds?: number;
+
+  @IsOptional()
+  @IsInt()
+  @Min(1)
+  @Max(30)
+  failureThreshold?: number;
+
+  // Grace period for slow-starting apps before liveness checks apply
+  @IsOptional()
+  @IsInt()
+  @Min(0)
+  @Max(1800)
+  startupTimeoutSeconds?: number;
+}
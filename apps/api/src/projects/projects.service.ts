@@ -7,6 +7,7 @@ import { EncryptionService } from 'src/common/utils/encryption.service';
 import { GithubAppService } from 'src/git/git.service';
 import { EnvironmentVariable } from 'generated/prisma/client';
 import { UrlUtils } from 'src/common/utils/url.utils';
+import { EnvScopeUtils } from 'src/common/utils/env-scope.utils';
 
 @Injectable()
 export class ProjectsService {
@@ -67,25 +68,42 @@ export class ProjectsService {
           runCommand: dto.runCommand,
           outputDirectory: dto.outputDirectory,
           pythonVersion: dto.pythonVersion,
+          dockerfilePath: dto.dockerfilePath,
+          dockerTarget: dto.dockerTarget,
           gitRepoOwner: dto.gitRepoOwner,
           gitRepoName: dto.gitRepoName,
           gitRepoId: dto.gitRepoId,
           gitBranch: dto.gitBranch,
           gitRepoUrl: dto.gitRepoUrl,
           gitCloneUrl: dto.gitCloneUrl,
+          deployStrategy: dto.deployStrategy,
+          healthCheck: dto.healthCheck ? { ...dto.healthCheck } : undefined,
+          minReplicas: dto.minReplicas,
+          maxReplicas: dto.maxReplicas,
+          targetCpuPercent: dto.targetCpuPercent,
+          targetMemoryPercent: dto.targetMemoryPercent,
         },
       });
 
       // B. Create Encrypted Env Vars
-      if (dto.envVars && dto.envVars.length > 0) {
+      const scopedVars = (dto.envVars ?? []).map((v) => ({
+        key: v.key,
+        value: v.value,
+        scope: v.scope ?? EnvScopeUtils.defaultScope(v.key),
+        buildSecret: v.buildSecret ?? EnvScopeUtils.defaultBuildSecret(v.key),
+      }));
+      if (scopedVars.length > 0) {
         await tx.environmentVariable.createMany({
-          data: dto.envVars.map((v) => ({
+          data: scopedVars.map((v) => ({
             key: v.key,
             value: this.encryptionService.encrypt(v.value),
+            scope: v.scope,
+            buildSecret: v.buildSecret,
             projectId: project.id,
           })),
         });
       }
+      const { envVars, buildEnvVars, buildSecrets } = EnvScopeUtils.split(scopedVars);
 
       // C. Create Initial Deployment
       const deploymentUrl = UrlUtils.generateDeploymentUrl(project.name);
@@ -118,6 +136,14 @@ export class ProjectsService {
         deploymentId: deployment.id,
         projectId: project.id,
         projectName: project.name,
+        strategy: project.deployStrategy,
+        healthCheck: dto.healthCheck,
+        scaling: {
+          minReplicas: project.minReplicas,
+          maxReplicas: project.maxReplicas,
+          targetCpuPercent: project.targetCpuPercent ?? undefined,
+          targetMemoryPercent: project.targetMemoryPercent ?? undefined,
+        },
         gitUrl: dto.gitCloneUrl,
         installationId: Number(matchingAccount.installationId),
         branch: project.gitBranch,
@@ -129,15 +155,17 @@ export class ProjectsService {
           buildCommand: project.buildCommand || undefined,
           runCommand: project.runCommand || undefined,
           outputDir: project.outputDirectory || undefined,
+          dockerfile: project.dockerfilePath || undefined,
+          dockerTarget: project.dockerTarget || undefined,
         },
         domains: [deploymentUrl],
-        // Decrypt env vars for the builder (it needs raw values)
-        envVars: {
-          ...(dto.envVars?.reduce((acc, curr) => ({
-              ...acc, [curr.key]: curr.value
-          }), {}) || {}),
+        // Raw values: runtime vars for the app, build vars for the builder
+        envVars,
+        buildEnvVars: {
+          ...buildEnvVars,
           ...(dto.pythonVersion ? { RAILPACK_PYTHON_VERSION: dto.pythonVersion } : {}),
-        }
+        },
+        buildSecrets,
       });
 
       this.logger.log(`[Queue] Triggered build for deployment ${deployment.id}`);
@@ -160,7 +188,7 @@ export class ProjectsService {
         },
         domains: true,
         envVars: {
-          select: { id: true, key: true, value: true, targets: true }
+          select: { id: true, key: true, value: true, targets: true, scope: true, buildSecret: true }
         },
       },
     });
@@ -187,7 +215,7 @@ export class ProjectsService {
       where: { id, userId },
       include: {
         envVars: { 
-          select: { id: true, key: true, value: true, targets: true } 
+          select: { id: true, key: true, value: true, targets: true, scope: true, buildSecret: true } 
         },
         domains: true,
         deployments: {
@@ -209,6 +237,7 @@ export class ProjectsService {
       where: { id },
       data: {
         ...dto,
+        healthCheck: dto.healthCheck ? { ...dto.healthCheck } : undefined,
       }
     });
   }
@@ -219,7 +248,7 @@ export class ProjectsService {
       include: {
         deployments: {
           where: {
-            status: { in: ['QUEUED', 'BUILDING', 'DEPLOYING', 'READY'] },
+            status: { in: ['QUEUED', 'BUILDING', 'DEPLOYING', 'READY', 'SLEEPING'] },
           },
           select: { id: true, status: true },
         },
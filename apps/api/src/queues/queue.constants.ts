@@ -1,9 +1,41 @@
 export const BUILD_QUEUE_NAME = 'build-queue';
 export const PROJECT_CLEANUP_QUEUE = 'project-cleanup-queue';
+export const PREVIEW_CLEANUP_QUEUE = 'preview-cleanup-queue';
 export const CANCEL_KEY_PREFIX = 'cancel:';
 
+// Probe settings stored on the project (Project.healthCheck). The worker
+// falls back to TCP probes when no path is set.
+export interface HealthCheckConfig {
+  path?: string;
+  expectedStatus?: number;
+  initialDelaySeconds?: number;
+  periodSeconds?: number;
+  timeoutSeconds?: number;
+  failureThreshold?: number;
+  startupTimeoutSeconds?: number;
+}
+
+// Replica bounds stored on the project. The worker adds an autoscaler when
+// maxReplicas is above minReplicas.
+export interface ScalingConfig {
+  minReplicas: number;
+  maxReplicas: number;
+  targetCpuPercent?: number;
+  targetMemoryPercent?: number;
+}
+
 // This is the payload your Go worker will expect
 export interface BuildJobData {
+  // Defaults to 'full' (clone, build and deploy)
+  mode?: 'full' | 'build-only';
+  // Defaults to 'PRODUCTION'. Previews get their own per-branch K8s
+  // resources and hostname, derived by the worker from `branch`.
+  environment?: 'PRODUCTION' | 'PREVIEW';
+  // Defaults to 'ROLLING'. 'BLUE_GREEN' switches traffic only after the
+  // new version passes its health check; 'CANARY' shifts it in steps.
+  strategy?: 'ROLLING' | 'BLUE_GREEN' | 'CANARY';
+  healthCheck?: HealthCheckConfig;
+  scaling?: ScalingConfig;
   deploymentId: string;
   projectId: string;
   projectName: string;
@@ -19,14 +51,57 @@ export interface BuildJobData {
     runCommand?: string;
     outputDir?: string;
     framework: string;
+    // Dockerfile path and stage; Railpack is used when there is no Dockerfile
+    dockerfile?: string;
+    dockerTarget?: string;
+  };
+  domains: string[];
+  // Runtime vars, set on the running app only
+  envVars: Record<string, string>;
+  // Build vars, passed to the build as build args or build secrets
+  buildEnvVars?: Record<string, string>;
+  // Build var keys a Dockerfile build gets as secrets instead of build args
+  buildSecrets?: string[];
+  previousDeploymentId?: string;
+  // ISO timestamp stamped by addBuildJob; the worker reports queue wait from it
+  queuedAt?: string;
+}
+
+// Deploys an image that was already built; the Go worker skips clone/build.
+// Rollbacks are deploy-only jobs with type 'rollback'.
+export interface ImageDeployJobData {
+  type?: 'rollback';
+  mode: 'deploy-only';
+  strategy?: 'ROLLING' | 'BLUE_GREEN' | 'CANARY';
+  healthCheck?: HealthCheckConfig;
+  scaling?: ScalingConfig;
+  deploymentId: string;
+  projectId: string;
+  projectName: string;
+  commitHash: string;
+  containerImage: string;
+  // Pins containerImage so a rebuilt tag can't change what gets deployed
+  imageDigest?: string;
+  sourceDeploymentId: string;
+  buildConfig: {
+    framework: string;
   };
   domains: string[];
   envVars: Record<string, string>;
   previousDeploymentId?: string;
+  queuedAt?: string;
 }
 
 export interface ProjectCleanupJobData {
   projectId: string;
   projectName: string;
   activeDeploymentIds: string[];
-}
\ No newline at end of file
+}
+
+// Tears down a branch's preview environment once its PR is closed
+export interface PreviewCleanupJobData {
+  projectId: string;
+  projectName: string;
+  branch: string;
+  deploymentIds: string[];
+}
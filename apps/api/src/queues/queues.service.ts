@@ -4,9 +4,12 @@ import Redis from 'ioredis';
 import {
   BUILD_QUEUE_NAME,
   PROJECT_CLEANUP_QUEUE,
+  PREVIEW_CLEANUP_QUEUE,
   CANCEL_KEY_PREFIX,
   BuildJobData,
+  ImageDeployJobData,
   ProjectCleanupJobData,
+  PreviewCleanupJobData,
 } from './queue.constants';
 
 @Injectable()
@@ -32,9 +35,9 @@ export class QueuesService implements OnModuleInit, OnModuleDestroy {
    * Add a build job to the Redis Queue using Raw RPUSH.
    * This is compatible with the Go Worker's BLPOP.
    */
-  async addBuildJob(data: BuildJobData) {
+  async addBuildJob(data: BuildJobData | ImageDeployJobData) {
     try {
-      const payload = JSON.stringify(data);
+      const payload = JSON.stringify({ ...data, queuedAt: new Date().toISOString() });
       await this.redis.rpush(BUILD_QUEUE_NAME, payload);
 
       this.logger.log(`[Queue] 🚀 Added build job for deployment ${data.deploymentId}`);
@@ -66,14 +69,35 @@ export class QueuesService implements OnModuleInit, OnModuleDestroy {
     }
   }
 
+  /**
+   * Push a preview cleanup job for the Go worker.
+   * Called when a pull request is closed or its branch is deleted — the
+   * worker removes that branch's preview resources only.
+   */
+  async addPreviewCleanupJob(data: PreviewCleanupJobData) {
+    try {
+      const payload = JSON.stringify(data);
+      await this.redis.lpush(PREVIEW_CLEANUP_QUEUE, payload);
+
+      this.logger.log(
+        `[Queue] 🧹 Added preview cleanup job for ${data.projectName}#${data.branch}`,
+      );
+    } catch (error) {
+      this.logger.error(`[Queue] Failed to add preview cleanup job`, error);
+      // Preview resources are labelled and removed with the project anyway
+    }
+  }
+
   /**
    * Signal cancellation for a deployment.
-   * Sets a Redis key that the Go worker polls between build steps.
+   * Publishes on the deployment's cancel channel so a running job stops
+   * immediately, and sets a key the worker checks for jobs still queued.
    */
   async publishCancelSignal(deploymentId: string) {
     const key = `${CANCEL_KEY_PREFIX}${deploymentId}`;
     // Set with 5-min TTL so it auto-expires even if never consumed
     await this.redis.set(key, '1', 'EX', 300);
+    await this.redis.publish(key, '1');
     this.logger.log(`[Queue] 🚫 Cancel signal published for deployment ${deploymentId}`);
   }
 
@@ -83,6 +107,7 @@ export class QueuesService implements OnModuleInit, OnModuleDestroy {
   async getQueueStatus() {
     const waiting = await this.redis.llen(BUILD_QUEUE_NAME);
     const cleanupPending = await this.redis.llen(PROJECT_CLEANUP_QUEUE);
-    return { waiting, cleanupPending };
+    const previewCleanupPending = await this.redis.llen(PREVIEW_CLEANUP_QUEUE);
+    return { waiting, cleanupPending, previewCleanupPending };
   }
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+import { Type } from 'class-transformer';
+import { IsString, IsNotEmpty, IsEnum, IsArray, IsOptional, IsUrl, ValidateNested } from 'class-validator';
+import { RegistryType } from 'generated/prisma/enums';
+
+export class RegistryCredentialItemDto {
+  // Set for an existing credential; its token is kept when none is sent
+  @IsOptional()
+  @IsString()
+  id?: string;
+
+  @IsEnum(RegistryType)
+  type: RegistryType;
+
+  @IsUrl({ require_tld: false, require_protocol: true })
+  url: string;
+
+  // npm scope ("@acme") or Go module pattern ("github.com/acme/*")
+  @IsOptional()
+  @IsString()
+  scope?: string;
+
+  @IsOptional()
+  @IsString()
+  username?: string;
+
+  @IsOptional()
+  @IsString()
+  @IsNotEmpty()
+  token?: string;
+}
+
+export class SaveRegistryCredentialsDto {
+  @IsArray()
+  @ValidateNested({ each: true })
+  @Type(() => RegistryCredentialItemDto)
+  credentials: RegistryCredentialItemDto[];
+}
@@ -0,0 +1,35 @@
+import {
+  Body,
+  Controller,
+  Get,
+  Param,
+  Put,
+  UseGuards,
+} from "@nestjs/common";
+import { JwtAuthGuard } from "src/common/guards/jwt-auth.guard";
+import { RegistryCredentialsService } from "./registry-credentials.service";
+import { GetCurrentUserId } from "src/common/decorators/get-current-user-id.decorator";
+import { SaveRegistryCredentialsDto } from "./dto/registry-credential.dto";
+
+@Controller("projects/:projectId/registry-credentials")
+@UseGuards(JwtAuthGuard)
+export class RegistryCredentialsController {
+  constructor(private readonly registryCredentialsService: RegistryCredentialsService) {}
+
+  @Put()
+  saveAll(
+    @GetCurrentUserId() userId: string,
+    @Param("projectId") projectId: string,
+    @Body() dto: SaveRegistryCredentialsDto
+  ) {
+    return this.registryCredentialsService.saveAll(userId, projectId, dto);
+  }
+
+  @Get()
+  findAll(
+    @GetCurrentUserId() userId: string,
+    @Param("projectId") projectId: string,
+  ) {
+    return this.registryCredentialsService.findAll(userId, projectId);
+  }
+}
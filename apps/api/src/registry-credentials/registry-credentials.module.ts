@@ -0,0 +1,12 @@
+import { Module } from '@nestjs/common';
+import { PrismaModule } from '../../prisma/prisma.module';
+import { RegistryCredentialsController } from './registry-credentials.controller';
+import { RegistryCredentialsService } from './registry-credentials.service';
+import { EncryptionService } from 'src/common/utils/encryption.service';
+
+@Module({
+  imports: [PrismaModule],
+  controllers: [RegistryCredentialsController],
+  providers: [RegistryCredentialsService, EncryptionService],
+})
+export class RegistryCredentialsModule {}
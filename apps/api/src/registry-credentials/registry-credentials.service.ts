@@ -0,0 +1,80 @@
+import { BadRequestException, Injectable, NotFoundException } from '@nestjs/common';
+import { PrismaService } from '../../prisma/prisma.service';
+import { SaveRegistryCredentialsDto } from './dto/registry-credential.dto';
+import { EncryptionService } from 'src/common/utils/encryption.service';
+
+@Injectable()
+export class RegistryCredentialsService {
+  constructor(
+    private prisma: PrismaService,
+    private encryption: EncryptionService
+  ) {}
+
+  // Tokens are write-only: the UI only learns whether one is set
+  async findAll(userId: string, projectId: string) {
+    const project = await this.prisma.project.findFirst({ where: { id: projectId, userId } });
+    if (!project) throw new NotFoundException('Project not found');
+
+    const credentials = await this.prisma.registryCredential.findMany({
+      where: { projectId },
+      orderBy: { createdAt: 'asc' }
+    });
+
+    return credentials.map(({ token, ...c }) => ({
+      ...c,
+      hasToken: token.length > 0,
+    }));
+  }
+
+  async saveAll(userId: string, projectId: string, dto: SaveRegistryCredentialsDto) {
+    // 1. Verify Ownership
+    const project = await this.prisma.project.findFirst({ where: { id: projectId, userId } });
+    if (!project) throw new NotFoundException('Project not found');
+
+    const existing = await this.prisma.registryCredential.findMany({
+      where: { projectId },
+      select: { id: true, token: true },
+    });
+    const existingTokens = new Map(existing.map((c) => [c.id, c.token]));
+
+    // 2. Resolve tokens: new ones are encrypted, omitted ones kept
+    const data = dto.credentials.map((c) => {
+      const token = c.token
+        ? this.encryption.encrypt(c.token)
+        : c.id ? existingTokens.get(c.id) : undefined;
+      if (!token) {
+        throw new BadRequestException(`A token is required for ${c.url}`);
+      }
+
+      return {
+        projectId,
+        type: c.type,
+        url: c.url,
+        scope: c.scope || null,
+        username: c.username || null,
+        token,
+      };
+    });
+
+    // 3. Transaction: Delete All -> Insert All
+    return this.prisma.$transaction(async (tx) => {
+      await tx.registryCredential.deleteMany({
+        where: { projectId }
+      });
+
+      if (data.length > 0) {
+        await tx.registryCredential.createMany({ data });
+      }
+
+      await tx.project.update({
+        where: { id: projectId },
+        data: {
+          configChanged: true,
+          updatedAt: new Date()
+        }
+      });
+
+      return { success: true, count: data.length };
+    });
+  }
+}
@@ -23,6 +23,10 @@ export class UpdateSettingsDto {
   @IsInt()
   globalTTLMinutes?: number;
 
+  @IsOptional()
+  @IsBoolean()
+  scaleToZero?: boolean;
+
   @IsOptional()
   @IsBoolean()
   autoDeploy?: boolean;
@@ -49,6 +49,11 @@ export class WebhooksController {
         await this.webhooksService.handlePushEvent(req.body);
         break;
 
+      case 'pull_request':
+        // eslint-disable-next-line @typescript-eslint/no-unsafe-argument
+        await this.webhooksService.handlePullRequestEvent(req.body);
+        break;
+
       case 'installation':
         // eslint-disable-next-line @typescript-eslint/no-unsafe-member-access
         this.logger.log(`Installation event: ${req.body.action} by ${req.body.sender?.login}`,);
@@ -3,16 +3,20 @@ import { ConfigService } from "@nestjs/config";
 import * as crypto from "crypto";
 import { PrismaService } from "prisma/prisma.service";
 import { QueuesService } from "src/queues/queues.service";
+import { HealthCheckConfig } from "src/queues/queue.constants";
 import { EncryptionService } from "src/common/utils/encryption.service";
+import { EnvScopeUtils } from "src/common/utils/env-scope.utils";
 import { Prisma } from "generated/prisma/client";
+import { DeploymentStatus, EnvironmentType } from "generated/prisma/enums";
 
 // ─────────────────────────────────────────────────────────────
 // Derived type matching the `include` in handlePushEvent
+// and handlePullRequestEvent
 // ─────────────────────────────────────────────────────────────
 
 type ProjectWithRelations = Prisma.ProjectGetPayload<{
   include: {
-    envVars: { select: { key: true; value: true } };
+    envVars: { select: { key: true; value: true; targets: true; scope: true; buildSecret: true } };
     domains: { select: { name: true } };
     deployments: { select: { id: true; status: true } };
   };
@@ -56,14 +60,60 @@ interface PushEventPayload {
   };
 }
 
+interface PullRequestEventPayload {
+  action: string; // "opened" | "reopened" | "synchronize" | "closed" | ...
+  number: number;
+  pull_request: {
+    title: string;
+    merged: boolean;
+    head: {
+      ref: string; // PR branch
+      sha: string;
+      repo: { full_name: string } | null; // null if the fork was deleted
+    };
+    base: {
+      ref: string; // branch the PR targets
+    };
+    user: {
+      login: string;
+    };
+  };
+  repository: {
+    full_name: string;
+    name: string;
+    owner: {
+      login: string;
+    };
+  };
+  sender: {
+    login: string;
+  };
+  installation?: {
+    id: number;
+  };
+}
+
+// Statuses that hold (or are about to hold) Kubernetes resources
+const ACTIVE_STATUSES: DeploymentStatus[] = [
+  "QUEUED",
+  "BUILDING",
+  "DEPLOYING",
+  "READY",
+  "SLEEPING",
+];
+
 // ─────────────────────────────────────────────────────────────
 // Deployment Lifecycle on Push:
 //
 // 1. No active deployment     → Create + deploy normally
-// 2. READY (live)             → Rolling update (zero downtime),
+// 2. READY / SLEEPING (live)  → Rolling update (zero downtime),
 //                               old deployment marked SUPERSEDED
 // 3. BUILDING / DEPLOYING     → Cancel via Redis signal, start new
 // 4. QUEUED                   → Cancel signal, start new
+//
+// Pull requests against a project's branch get the same lifecycle in a
+// PREVIEW environment keyed by the PR branch. Closing the PR (or deleting
+// the branch) tears the preview down.
 // ─────────────────────────────────────────────────────────────
 
 @Injectable()
@@ -110,16 +160,17 @@ export class WebhooksService {
   // ─────────────────────────────────────────────────────────
 
   async handlePushEvent(payload: PushEventPayload) {
-    // Ignore branch deletions (e.g. "git push origin --delete feature-x")
-    if (payload.deleted) {
-      this.logger.debug("Ignoring branch deletion event");
-      return;
-    }
-
     // Extract branch name: "refs/heads/main" → "main"
     const branch = payload.ref.replace("refs/heads/", "");
     const repoOwner = payload.repository.owner.login;
     const repoName = payload.repository.name;
+
+    // Branch deletions (e.g. "git push origin --delete feature-x") only
+    // retire that branch's previews
+    if (payload.deleted) {
+      await this.cleanupPreviews(repoOwner, repoName, branch);
+      return;
+    }
     const commitHash = payload.after;
     const installationId = payload.installation?.id;
 
@@ -143,7 +194,7 @@ export class WebhooksService {
       },
       include: {
         envVars: {
-          select: { key: true, value: true },
+          select: { key: true, value: true, targets: true, scope: true, buildSecret: true },
         },
         domains: {
           select: { name: true },
@@ -151,7 +202,10 @@ export class WebhooksService {
         },
         deployments: {
           where: {
-            status: { in: ["QUEUED", "BUILDING", "DEPLOYING", "READY"] },
+            status: { in: ACTIVE_STATUSES },
+            // Prebuilds don't hold traffic and shouldn't be cancelled by a push
+            mode: { not: "BUILD_ONLY" },
+            environment: "PRODUCTION",
           },
           orderBy: { startedAt: "desc" },
           take: 1,
@@ -183,6 +237,7 @@ export class WebhooksService {
 
       try {
         await this.triggerDeployment(project, {
+          environment: "PRODUCTION",
           branch,
           commitHash,
           installationId,
@@ -200,6 +255,150 @@ export class WebhooksService {
     }
   }
 
+  // ─────────────────────────────────────────────────────────
+  // Pull Request Event Handler (preview environments)
+  // ─────────────────────────────────────────────────────────
+
+  async handlePullRequestEvent(payload: PullRequestEventPayload) {
+    const pr = payload.pull_request;
+    const repoOwner = payload.repository.owner.login;
+    const repoName = payload.repository.name;
+    const branch = pr.head.ref;
+
+    if (payload.action === "closed") {
+      await this.cleanupPreviews(repoOwner, repoName, branch);
+      return;
+    }
+
+    if (!["opened", "reopened", "synchronize"].includes(payload.action)) {
+      this.logger.debug(`Ignoring pull_request action: ${payload.action}`);
+      return;
+    }
+
+    // The worker clones the project's repo, so a fork's branch isn't there
+    if (pr.head.repo?.full_name !== payload.repository.full_name) {
+      this.logger.debug(
+        `Skipping preview for PR #${payload.number} from a fork`,
+      );
+      return;
+    }
+
+    const installationId = payload.installation?.id;
+    if (!installationId) {
+      this.logger.warn("Pull request event missing installation ID — skipping");
+      return;
+    }
+
+    this.logger.log(
+      `PR #${payload.number} ${payload.action} on ${repoOwner}/${repoName} ` +
+        `(${branch} → ${pr.base.ref}, ${pr.head.sha.slice(0, 8)})`,
+    );
+
+    // Previews are built for PRs that target a project's branch
+    const projects = await this.prisma.project.findMany({
+      where: {
+        gitRepoOwner: repoOwner,
+        gitRepoName: repoName,
+        gitBranch: pr.base.ref,
+      },
+      include: {
+        envVars: {
+          select: { key: true, value: true, targets: true, scope: true, buildSecret: true },
+        },
+        domains: {
+          select: { name: true },
+          orderBy: { createdAt: "asc" },
+        },
+        deployments: {
+          where: {
+            status: { in: ACTIVE_STATUSES },
+            mode: { not: "BUILD_ONLY" },
+            environment: "PREVIEW",
+            branch,
+          },
+          orderBy: { startedAt: "desc" },
+          take: 1,
+          select: { id: true, status: true },
+        },
+      },
+    });
+
+    for (const project of projects) {
+      if (!project.autoDeploy) {
+        this.logger.debug(
+          `Skipping preview for ${project.name} — autoDeploy is disabled`,
+        );
+        continue;
+      }
+
+      try {
+        await this.triggerDeployment(project, {
+          environment: "PREVIEW",
+          branch,
+          commitHash: pr.head.sha,
+          installationId,
+          commitMessage: pr.title,
+          commitAuthor: pr.user.login,
+        });
+      } catch (error) {
+        this.logger.error(
+          `Failed to trigger preview for ${project.name}#${branch}`,
+          error,
+        );
+      }
+    }
+  }
+
+  // ─────────────────────────────────────────────────────────
+  // Preview Cleanup (PR closed / branch deleted)
+  // ─────────────────────────────────────────────────────────
+
+  private async cleanupPreviews(
+    repoOwner: string,
+    repoName: string,
+    branch: string,
+  ) {
+    const projects = await this.prisma.project.findMany({
+      where: { gitRepoOwner: repoOwner, gitRepoName: repoName },
+      select: {
+        id: true,
+        name: true,
+        deployments: {
+          where: {
+            status: { in: ACTIVE_STATUSES },
+            environment: "PREVIEW",
+            branch,
+          },
+          select: { id: true, status: true },
+        },
+      },
+    });
+
+    for (const project of projects) {
+      if (project.deployments.length === 0) continue;
+
+      for (const deployment of project.deployments) {
+        if (deployment.status !== "READY" && deployment.status !== "SLEEPING") {
+          await this.queuesService.publishCancelSignal(deployment.id);
+        }
+      }
+
+      await this.prisma.deployment.updateMany({
+        where: { id: { in: project.deployments.map((d) => d.id) } },
+        data: { status: "EXPIRED", finishedAt: new Date() },
+      });
+
+      await this.queuesService.addPreviewCleanupJob({
+        projectId: project.id,
+        projectName: project.name,
+        branch,
+        deploymentIds: project.deployments.map((d) => d.id),
+      });
+
+      this.logger.log(`Retired preview ${project.name}#${branch}`);
+    }
+  }
+
   // ─────────────────────────────────────────────────────────
   // Deployment Trigger (mirrors the flow in projects.create)
   // ─────────────────────────────────────────────────────────
@@ -207,6 +406,7 @@ export class WebhooksService {
   private async triggerDeployment(
     project: ProjectWithRelations,
     opts: {
+      environment: EnvironmentType;
       branch: string;
       commitHash: string;
       installationId: number;
@@ -236,23 +436,33 @@ export class WebhooksService {
           where: { id: currentDeployment.id },
           data: { status: "CANCELED" },
         });
-      } else if (status === "READY") {
+      } else if (status === "READY" || status === "SLEEPING") {
         previousDeploymentId = currentDeployment.id;
       }
     }
 
     // ── Build domain list from existing project domains ──
-    const domains: string[] = project.domains.map((d) => d.name);
-
-    // ── Decrypt env vars (stored encrypted in DB) ────────
-    const envVars: Record<string, string> = {};
-    for (const env of project.envVars) {
-      envVars[env.key] = this.encryptionService.decrypt(env.value);
-    }
+    // Previews get a per-branch host from the worker instead
+    const isPreview = opts.environment === "PREVIEW";
+    const domains: string[] = isPreview
+      ? []
+      : project.domains.map((d) => d.name);
+
+    // ── Decrypt env vars targeting this environment ──────
+    const { envVars, buildEnvVars, buildSecrets } = EnvScopeUtils.split(
+      project.envVars
+        .filter((env) => env.targets.includes(opts.environment))
+        .map((env) => ({
+          key: env.key,
+          value: this.encryptionService.decrypt(env.value),
+          scope: env.scope,
+          buildSecret: env.buildSecret,
+        })),
+    );
 
     // Add python version if set on project
     if (project.pythonVersion) {
-      envVars["RAILPACK_PYTHON_VERSION"] = project.pythonVersion;
+      buildEnvVars["RAILPACK_PYTHON_VERSION"] = project.pythonVersion;
     }
 
     // ── Fetch system config for deployment region ────────
@@ -268,6 +478,7 @@ export class WebhooksService {
         projectId: project.id,
         initiatorId: project.userId,
         status: "QUEUED",
+        environment: opts.environment,
         deploymentUrl,
         branch: opts.branch,
         commitHash: opts.commitHash,
@@ -280,11 +491,21 @@ export class WebhooksService {
 
     this.logger.log(
       `Created deployment ${deployment.id} for ${project.name} ` +
-        `(commit: ${opts.commitHash.slice(0, 8)}, trigger: webhook)`,
+        `(commit: ${opts.commitHash.slice(0, 8)}, trigger: webhook, ` +
+        `env: ${opts.environment})`,
     );
 
     // ── Push build job to Redis queue ────────────────────
     await this.queuesService.addBuildJob({
+      environment: opts.environment,
+      strategy: project.deployStrategy,
+      healthCheck: (project.healthCheck as HealthCheckConfig | null) ?? undefined,
+      scaling: {
+        minReplicas: project.minReplicas,
+        maxReplicas: project.maxReplicas,
+        targetCpuPercent: project.targetCpuPercent ?? undefined,
+        targetMemoryPercent: project.targetMemoryPercent ?? undefined,
+      },
       deploymentId: deployment.id,
       projectId: project.id,
       projectName: project.name,
@@ -299,9 +520,13 @@ export class WebhooksService {
         buildCommand: project.buildCommand || undefined,
         runCommand: project.runCommand || undefined,
         outputDir: project.outputDirectory || undefined,
+        dockerfile: project.dockerfilePath || undefined,
+        dockerTarget: project.dockerTarget || undefined,
       },
       domains,
       envVars,
+      buildEnvVars,
+      buildSecrets,
       previousDeploymentId,
     });
 
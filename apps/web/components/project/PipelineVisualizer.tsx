@@ -40,6 +40,13 @@ function getStepsFromStatus(deploymentStatus?: DeploymentStatus): PipelineStep[]
       { icon: Rocket, label: "Deploy", status: "pending" },
       { icon: Globe, label: "Ready", status: "pending" },
     ],
+    [DeploymentStatus.BUILT]: [
+      { icon: Clock, label: "Queued", sublabel: "Done", status: "completed" },
+      { icon: GitCommit, label: "Source", sublabel: "Cloned", status: "completed" },
+      { icon: Hammer, label: "Build", sublabel: "Success", status: "completed" },
+      { icon: Rocket, label: "Deploy", sublabel: "Not deployed", status: "pending" },
+      { icon: Globe, label: "Ready", status: "pending" },
+    ],
     [DeploymentStatus.DEPLOYING]: [
       { icon: Clock, label: "Queued", sublabel: "Done", status: "completed" },
       { icon: GitCommit, label: "Source", sublabel: "Cloned", status: "completed" },
@@ -82,6 +89,13 @@ function getStepsFromStatus(deploymentStatus?: DeploymentStatus): PipelineStep[]
       { icon: Rocket, label: "Deploy", sublabel: "Superseded", status: "completed" },
       { icon: Globe, label: "Ready", status: "completed" },
     ],
+    [DeploymentStatus.SLEEPING]: [
+      { icon: Clock, label: "Queued", sublabel: "Done", status: "completed" },
+      { icon: GitCommit, label: "Source", sublabel: "Cloned", status: "completed" },
+      { icon: Hammer, label: "Build", sublabel: "Success", status: "completed" },
+      { icon: Rocket, label: "Deploy", sublabel: "Live", status: "completed" },
+      { icon: Globe, label: "Ready", sublabel: "Sleeping", status: "completed" },
+    ],
   };
 
   return map[s];
@@ -1,6 +1,6 @@
 import { FRAMEWORK_ICONS } from "@/types/git";
 import { DeploymentStatus } from "@/types/project";
-import { Ban, BrushCleaning, CheckCircle2, Hammer, Hourglass, Recycle, Rocket, XCircle } from "lucide-react";
+import { Ban, BrushCleaning, CheckCircle2, Hammer, Hourglass, Moon, Package, Recycle, Rocket, XCircle } from "lucide-react";
 import Image from "next/image";
 import { cloneElement, isValidElement, JSX, ReactElement } from "react";
 
@@ -54,6 +54,13 @@ const STATUS_CONFIG: Record<DeploymentStatus, StatusConfig> = {
     glow: "shadow-[0_0_10px_rgba(59,130,246,0.4)]",
     icon: <Hammer className="text-blue-400 animate-hammer" />,
   },
+  BUILT: {
+    label: "Built",
+    color: "bg-sky-500",
+    text: "text-sky-500",
+    glow: "shadow-[0_0_10px_rgba(14,165,233,0.4)]",
+    icon: <Package className="text-sky-400" />,
+  },
   DEPLOYING: {
     label: "Deploying",
     color: "bg-purple-500",
@@ -88,6 +95,13 @@ const STATUS_CONFIG: Record<DeploymentStatus, StatusConfig> = {
     text: "text-gray-500",
     glow: "shadow-[0_0_10px_rgba(161,161,170,0.35)]",
     icon: <Recycle className="text-zinc-400" />,
+  },
+  SLEEPING: {
+    label: "Sleeping",
+    color: "bg-indigo-500",
+    text: "text-indigo-500",
+    glow: "shadow-[0_0_10px_rgba(99,102,241,0.4)]",
+    icon: <Moon className="text-indigo-400" />,
   }
 };
 
@@ -2,12 +2,14 @@
 export enum DeploymentStatus {
   QUEUED = 'QUEUED',
   BUILDING = 'BUILDING',
+  BUILT = 'BUILT',
   DEPLOYING = 'DEPLOYING',
   READY = 'READY',
   FAILED = 'FAILED',
   CANCELED = 'CANCELED',
   EXPIRED = 'EXPIRED',
   SUPERSEDED = 'SUPERSEDED',
+  SLEEPING = 'SLEEPING',
 }
 
 export enum DomainDnsStatus {
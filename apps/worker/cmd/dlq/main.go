@@ -0,0 +1,115 @@
+// Command dlq inspects and replays jobs on the build dead-letter queue.
+//
+//	dlq list [limit]
+//	dlq replay <deploymentId>
+//
+// It reads the same environment as the worker, so it is meant to be run
+// inside the worker pod: kubectl exec deploy/worker -- dlq list
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/api"
+	"code2cloud/worker/internal/config"
+	"code2cloud/worker/internal/queue"
+	"code2cloud/worker/internal/types"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fail("failed to load config: %v", err)
+	}
+
+	logger := zap.NewNop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Use a distinct ID so the CLI never touches a real worker's processing list
+	q, err := queue.New(cfg.RedisURL, cfg.QueueName, cfg.WorkerID+"-dlq", logger)
+	if err != nil {
+		fail("failed to connect to queue: %v", err)
+	}
+	defer q.Close()
+
+	switch os.Args[1] {
+	case "list":
+		var limit int64 = 50
+		if len(os.Args) > 2 {
+			n, err := strconv.ParseInt(os.Args[2], 10, 64)
+			if err != nil {
+				fail("invalid limit %q", os.Args[2])
+			}
+			limit = n
+		}
+
+		letters, err := q.DeadLetters(ctx, limit)
+		if err != nil {
+			fail("%v", err)
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		for _, letter := range letters {
+			// Never print user secrets to the terminal
+			letter.Job.EnvVars = redactEnv(letter.Job.EnvVars)
+			letter.Job.BuildEnvVars = redactEnv(letter.Job.BuildEnvVars)
+			enc.Encode(letter)
+		}
+
+	case "replay":
+		if len(os.Args) < 3 {
+			usage()
+		}
+
+		job, err := q.ReplayDeadLetter(ctx, os.Args[2])
+		if err != nil {
+			fail("%v", err)
+		}
+
+		apiClient := api.New(cfg.APIBaseURL, cfg.WorkerAPIKey, logger)
+		if err := apiClient.UpdateDeploymentStatus(ctx, job.DeploymentID, types.StatusQueued); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: job requeued but status update failed: %v\n", err)
+		}
+
+		fmt.Printf("Requeued deployment %s (%s)\n", job.DeploymentID, job.ProjectName)
+
+	default:
+		usage()
+	}
+}
+
+func redactEnv(envVars map[string]string) map[string]string {
+	redacted := make(map[string]string, len(envVars))
+	for key := range envVars {
+		redacted[key] = "***"
+	}
+	return redacted
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dlq list [limit]")
+	fmt.Fprintln(os.Stderr, "       dlq replay <deploymentId>")
+	os.Exit(2)
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -12,6 +12,8 @@ import (
 	"code2cloud/worker/internal/builder"
 	"code2cloud/worker/internal/config"
 	"code2cloud/worker/internal/git"
+	"code2cloud/worker/internal/health"
+	"code2cloud/worker/internal/metrics"
 	"code2cloud/worker/internal/worker"
 	"github.com/joho/godotenv"
 )
@@ -58,11 +60,18 @@ func main() {
 	logger.Info("Git ready", zap.String("version", gitResult.GitVersion))
 
 	// Verify Railpack
-	railpackResult, err := builder.Verify(ctx, cfg.BuildkitAddr, logger)
-	if err != nil {
-		// logger.Fatal("Railpack verification failed", zap.Error(err))
+	// A missing railpack isn't fatal: the readiness probe reports it and
+	// the worker holds off taking jobs until it's fixed
+	if cfg.BuildBackend == builder.BackendBuildkit {
+		railpackResult, err := builder.Verify(ctx, cfg.BuildkitAddr, logger)
+		if err != nil {
+			logger.Error("Railpack verification failed, worker will report not ready", zap.Error(err))
+		} else {
+			logger.Info("Railpack ready", zap.String("version", railpackResult.RailpackVersion))
+		}
+	} else {
+		logger.Warn("Using a non-default build backend", zap.String("backend", cfg.BuildBackend))
 	}
-	logger.Info("Railpack ready", zap.String("version", railpackResult.RailpackVersion))
 
 	// Step 4: Initialize Worker
 	initCtx, initCancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -78,7 +87,16 @@ func main() {
 		zap.String("queue", cfg.QueueName),
 	)
 
-	// Step 5: Setup Graceful Shutdown
+	// Step 5: Start Metrics, Health and Activator Servers
+	// Run on their own context so probes and /metrics stay up while jobs drain
+	serverCtx, stopServers := context.WithCancel(context.Background())
+	defer stopServers()
+
+	go metrics.Serve(serverCtx, cfg.MetricsAddr, logger)
+	go health.Serve(serverCtx, cfg.HealthAddr, w.Health(), logger)
+	go w.Activator().Serve(serverCtx, cfg.ActivatorAddr)
+
+	// Step 6: Setup Graceful Shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -87,13 +105,20 @@ func main() {
 
 	go func() {
 		sig := <-sigChan
-		logger.Info("Received shutdown signal",
+		logger.Info("Received shutdown signal, draining in-flight jobs",
 			zap.String("signal", sig.String()),
+			zap.Duration("timeout", cfg.ShutdownTimeout),
 		)
 		cancel()
+
+		// A second signal skips the drain
+		sig = <-sigChan
+		logger.Fatal("Received second shutdown signal, exiting immediately",
+			zap.String("signal", sig.String()),
+		)
 	}()
 
-	// Step 6: Start Worker (Blocking)
+	// Step 7: Start Worker (Blocking)
 	if err := w.Start(ctx); err != nil && err != context.Canceled {
 		logger.Fatal("Worker failed", zap.Error(err))
 	}
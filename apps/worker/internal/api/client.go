@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/types"
 )
 
 type Client struct {
@@ -63,18 +65,26 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	return resp, nil
 }
 
-// GET request
+// GET request. Network errors and 5xx/429 responses come back transient,
+// so a job that needs the data is retried through a short API outage.
 func (c *Client) get(ctx context.Context, path string, result interface{}) error {
 	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
-		return err
+		if ctx.Err() != nil {
+			return err
+		}
+		return types.Transient(err)
 	}
 	defer resp.Body.Close()
 
 	// Check status code
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		err := fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return types.Transient(err)
+		}
+		return err
 	}
 
 	// Decode response
@@ -147,6 +157,6 @@ func (c *Client) HealthCheck(ctx context.Context) error {
 	if err := c.get(ctx, "/health", &result); err != nil {
 		return fmt.Errorf("health check failed: %w", err)
 	}
-	c.logger.Info("API health check passed")
+	c.logger.Debug("API health check passed")
 	return nil
 }
\ No newline at end of file
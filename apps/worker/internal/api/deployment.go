@@ -10,9 +10,45 @@ import (
 )
 
 type DeploymentStatusUpdate struct {
-	Status         string  `json:"status"`
-	ContainerImage *string `json:"containerImage,omitempty"`
-	DeploymentURL  *string `json:"deploymentUrl,omitempty"`
+	Status         string             `json:"status"`
+	ContainerImage *string            `json:"containerImage,omitempty"`
+	ImageDigest    *string            `json:"imageDigest,omitempty"`
+	DeploymentURL  *string            `json:"deploymentUrl,omitempty"`
+	Failure        *DeploymentFailure `json:"failure,omitempty"`
+	Timings        *DeploymentTimings `json:"timings,omitempty"`
+}
+
+// DeploymentTimings is the per-phase time breakdown of a deployment, in
+// milliseconds. Phases that never ran are omitted.
+type DeploymentTimings struct {
+	QueueWaitMs  int64 `json:"queueWaitMs,omitempty"`
+	TokenFetchMs int64 `json:"tokenFetchMs,omitempty"`
+	CloneMs      int64 `json:"cloneMs,omitempty"`
+	PrepareMs    int64 `json:"prepareMs,omitempty"`
+	BuildMs      int64 `json:"buildMs,omitempty"`
+	ApplyMs      int64 `json:"applyMs,omitempty"`
+	ReadinessMs  int64 `json:"readinessMs,omitempty"`
+	TotalMs      int64 `json:"totalMs"`
+}
+
+// FailurePhase is the pipeline phase a deployment failed in
+type FailurePhase string
+
+const (
+	PhaseSetup  FailurePhase = "SETUP"
+	PhaseClone  FailurePhase = "CLONE"
+	PhaseBuild  FailurePhase = "BUILD"
+	PhaseDeploy FailurePhase = "DEPLOY"
+	PhaseHealth FailurePhase = "HEALTH"
+)
+
+// DeploymentFailure is the structured reason attached to a FAILED status
+type DeploymentFailure struct {
+	Phase    FailurePhase `json:"phase"`
+	Code     string       `json:"code"`
+	Message  string       `json:"message"`
+	ExitCode *int         `json:"exitCode,omitempty"`
+	LogTail  []string     `json:"logTail,omitempty"`
 }
 
 type Deployment struct {
@@ -58,13 +94,17 @@ func (c *Client) UpdateDeploymentStatus(ctx context.Context, id string, status t
 	return nil
 }
 
-// UpdateDeploymentWithImage updates status and sets container image
-func (c *Client) UpdateDeploymentWithImage(ctx context.Context, id string, status types.DeploymentStatus, imageName string) error {
+// UpdateDeploymentWithImage updates status and sets container image and,
+// when known, its digest
+func (c *Client) UpdateDeploymentWithImage(ctx context.Context, id string, status types.DeploymentStatus, imageName string, digest string) error {
 	path := fmt.Sprintf("/internal/deployments/%s/status", id)
 	body := DeploymentStatusUpdate{
 		Status:         string(status),
 		ContainerImage: &imageName,
 	}
+	if digest != "" {
+		body.ImageDigest = &digest
+	}
 
 	return c.patch(ctx, path, body)
 }
@@ -80,16 +120,55 @@ func (c *Client) UpdateDeploymentWithURL(ctx context.Context, id string, status
 	return c.patch(ctx, path, body)
 }
 
-// FailDeployment marks deployment as failed with error message
-func (c *Client) FailDeployment(ctx context.Context, id string, errorMsg string) error {
+// CompleteDeployment marks deployment as READY with its URL and timings
+func (c *Client) CompleteDeployment(ctx context.Context, id string, url string, timings *DeploymentTimings) error {
 	path := fmt.Sprintf("/internal/deployments/%s/status", id)
 	body := DeploymentStatusUpdate{
-		Status: string(types.StatusFailed),
+		Status:        string(types.StatusReady),
+		DeploymentURL: &url,
+		Timings:       timings,
+	}
+
+	return c.patch(ctx, path, body)
+}
+
+// CompleteBuild marks a build-only deployment as BUILT with the pushed image
+// and its digest
+func (c *Client) CompleteBuild(ctx context.Context, id string, image string, digest string, timings *DeploymentTimings) error {
+	path := fmt.Sprintf("/internal/deployments/%s/status", id)
+	body := DeploymentStatusUpdate{
+		Status:         string(types.StatusBuilt),
+		ContainerImage: &image,
+		Timings:        timings,
+	}
+	if digest != "" {
+		body.ImageDigest = &digest
 	}
 
 	return c.patch(ctx, path, body)
 }
 
+// FailDeployment marks deployment as failed with a structured failure record
+// and the timings of the phases that ran
+func (c *Client) FailDeployment(ctx context.Context, id string, failure *DeploymentFailure, timings *DeploymentTimings) error {
+	path := fmt.Sprintf("/internal/deployments/%s/status", id)
+	body := DeploymentStatusUpdate{
+		Status:  string(types.StatusFailed),
+		Failure: failure,
+		Timings: timings,
+	}
+
+	if err := c.patch(ctx, path, body); err != nil {
+		c.logger.Warn("Failed to mark deployment as failed",
+			zap.String("deploymentId", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+
+	return nil
+}
+
 // GetExpiredDeployments fetches deployments past their TTL
 func (c *Client) GetExpiredDeployments(ctx context.Context) ([]types.ExpiredDeployment, error) {
 	var deployments []types.ExpiredDeployment
@@ -105,4 +184,15 @@ func (c *Client) GetExpiredDeployments(ctx context.Context) ([]types.ExpiredDepl
 func (c *Client) CleanupDeployment(ctx context.Context, id string) error {
 	path := fmt.Sprintf("/internal/deployments/%s/resources", id)
 	return c.delete(ctx, path)
-}
\ No newline at end of file
+}
+
+// ReportReplicas sends the current and desired replica counts of live
+// deployments
+func (c *Client) ReportReplicas(ctx context.Context, counts []types.ReplicaCount) error {
+	body := map[string]interface{}{"counts": counts}
+
+	if err := c.post(ctx, "/internal/deployments/replicas", body, nil); err != nil {
+		return fmt.Errorf("failed to report replica counts: %w", err)
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ type DeploymentNotification struct {
 	ProjectName   string  `json:"projectName"`
 	DeploymentURL *string `json:"deploymentUrl,omitempty"`
 	Message       *string `json:"message,omitempty"`
+	Duration      *int    `json:"duration,omitempty"`
 }
 
 func (c *Client) SendDeploymentNotification(ctx context.Context, notification DeploymentNotification) error {
@@ -42,6 +43,7 @@ func (c *Client) NotifySuccess(ctx context.Context, deploymentID, projectName, u
 		Status:        "READY",
 		ProjectName:   projectName,
 		DeploymentURL: &url,
+		Duration:      &duration,
 	})
 }
 
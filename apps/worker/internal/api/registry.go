@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/types"
+)
+
+// GetRegistryCredentials fetches a project's private package registry
+// credentials, decrypted, for its builds
+func (c *Client) GetRegistryCredentials(ctx context.Context, projectID string) ([]types.RegistryCredential, error) {
+	path := fmt.Sprintf("/internal/projects/%s/registry-credentials", projectID)
+
+	var credentials []types.RegistryCredential
+	if err := c.get(ctx, path, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to get registry credentials: %w", err)
+	}
+
+	c.logger.Debug("Got registry credentials",
+		zap.String("projectId", projectID),
+		zap.Int("count", len(credentials)),
+	)
+
+	return credentials, nil
+}
@@ -0,0 +1,90 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+)
+
+// Backend runs the steps of a build that need build tooling. Builder
+// decides what to build (Dockerfile or Railpack, args, secrets) and owns
+// logging and timeouts; a Backend only executes it.
+type Backend interface {
+	// Name identifies the backend in logs
+	Name() string
+
+	// Prepare generates the Railpack build plan in the source directory.
+	// Dockerfile builds skip it.
+	Prepare(ctx context.Context, req PrepareRequest) error
+
+	// Build builds the image and pushes it to the registry
+	Build(ctx context.Context, req BuildRequest) (*BuildOutput, error)
+
+	// HealthCheck reports whether the backend can take builds
+	HealthCheck(ctx context.Context) error
+}
+
+// railpackPlanFile is where Prepare writes the plan, in the source
+// directory.
+const railpackPlanFile = "railpack-plan.json"
+
+type PrepareRequest struct {
+	SourcePath string
+
+	// Overrides for the detected build and start commands
+	BuildCommand string
+	StartCommand string
+
+	// Passed to Railpack as --env names, with the values in its
+	// environment
+	Env map[string]string
+
+	Output io.Writer
+}
+
+type BuildRequest struct {
+	SourcePath string
+	ImageName  string
+
+	// Absolute path of the Dockerfile; the Railpack plan is built when empty
+	Dockerfile string
+	Target     string
+
+	// Dockerfile ARG values
+	BuildArgs map[string]string
+
+	// Values exposed to the build as secrets, by id
+	Secrets map[string]string
+
+	Output io.Writer
+}
+
+type BuildOutput struct {
+	// Pushed image digest (sha256:...), if the backend reports one
+	Digest string
+
+	// Whether any build step was served from the cache
+	CacheUsed bool
+}
+
+const (
+	// BackendBuildkit shells out to railpack and buildctl
+	BackendBuildkit = "buildkit"
+
+	// BackendFake builds nothing; see FakeBackend
+	BackendFake = "fake"
+)
+
+// NewBackend returns the backend called name.
+func NewBackend(name string, config Config, logger *zap.Logger) (Backend, error) {
+	switch name {
+	case "", BackendBuildkit:
+		return NewBuildkitBackend(config, logger), nil
+	case BackendFake:
+		return NewFakeBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown build backend %q", name)
+	}
+}
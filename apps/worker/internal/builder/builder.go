@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -15,18 +14,25 @@ import (
 
 type Builder struct {
 	config     Config
+	backend    Backend
 	logFactory *logging.Factory
 	logger     *zap.Logger
 }
 
-func NewBuilder(config Config, logFactory *logging.Factory, logger *zap.Logger) *Builder {
+func NewBuilder(config Config, backend Backend, logFactory *logging.Factory, logger *zap.Logger) *Builder {
 	return &Builder{
 		config:     config,
+		backend:    backend,
 		logFactory: logFactory,
 		logger:     logger,
 	}
 }
 
+// HealthCheck reports whether the build backend can take builds.
+func (b *Builder) HealthCheck(ctx context.Context) error {
+	return b.backend.HealthCheck(ctx)
+}
+
 func (b *Builder) Build(ctx context.Context, opts Options) (*Result, error) {
 	startTime := time.Now()
 
@@ -41,6 +47,7 @@ func (b *Builder) Build(ctx context.Context, opts Options) (*Result, error) {
 		zap.String("sourcePath", opts.SourcePath),
 		zap.String("imageName", opts.ImageName),
 		zap.String("framework", opts.BuildConfig.Framework),
+		zap.String("backend", b.backend.Name()),
 	)
 
 	// ─────────────────────────────────────────────────────────
@@ -50,20 +57,64 @@ func (b *Builder) Build(ctx context.Context, opts Options) (*Result, error) {
 		return nil, fmt.Errorf("source path does not exist: %s", opts.SourcePath)
 	}
 
+	// A Dockerfile in the repo (or the one configured for the project)
+	// takes precedence over Railpack
+	dockerfile, err := resolveDockerfile(opts.SourcePath, opts.BuildConfig.DockerfilePath)
+	if err != nil {
+		return nil, err
+	}
+
 	buildLog.Log(fmt.Sprintf("Building from: %s", opts.SourcePath))
 	buildLog.Log(fmt.Sprintf("Target image: %s", opts.ImageName))
+	if dockerfile != "" {
+		buildLog.Log(fmt.Sprintf("🐳 Building from Dockerfile: %s", relativePath(opts.SourcePath, dockerfile)))
+		if opts.BuildConfig.DockerTarget != "" {
+			buildLog.Log(fmt.Sprintf("   Target stage: %s", opts.BuildConfig.DockerTarget))
+		}
+	} else {
+		buildLog.Log("No Dockerfile found, building with Railpack")
+	}
 
 	// ─────────────────────────────────────────────────────────
-	// Step 2: Build command arguments
+	// Step 2: Describe the build
 	// ─────────────────────────────────────────────────────────
-	args := b.buildArgs(opts)
+	var req BuildRequest
+	if dockerfile != "" {
+		req = dockerfileRequest(opts, dockerfile)
+
+		// Names only; which vars went where is what a missing ARG comes
+		// down to
+		if len(req.BuildArgs) > 0 {
+			buildLog.Log("   Build args: " + strings.Join(sortedKeys(req.BuildArgs), ", "))
+		}
+		if len(req.Secrets) > 0 {
+			buildLog.Log("   Build secrets: " + strings.Join(sortedKeys(req.Secrets), ", "))
+		}
+		b.logger.Info("Dockerfile build inputs",
+			zap.String("deploymentId", opts.DeploymentID),
+			zap.Strings("buildArgs", sortedKeys(req.BuildArgs)),
+			zap.Strings("secrets", sortedKeys(req.Secrets)),
+		)
+	} else {
+		req = railpackRequest(opts)
+	}
+	req.Output = buildLog
 
-	buildLog.Log("")
-	buildLog.Log("$ buildctl " + strings.Join(sanitizeArgs(args), " "))
-	buildLog.Log("")
+	var registry *registryConfig
+	if len(opts.RegistryCredentials) > 0 {
+		registry, err = registrySecrets(opts.RegistryCredentials)
+		if err != nil {
+			return nil, err
+		}
+		for key, val := range registry.secrets {
+			req.Secrets[key] = val
+		}
+
+		buildLog.Log("🔑 Registry credentials: " + strings.Join(registrySummary(opts.RegistryCredentials), ", "))
+	}
 
 	// ─────────────────────────────────────────────────────────
-	// Step 3: Create command with timeout
+	// Step 3: Apply the build timeout
 	// ─────────────────────────────────────────────────────────
 	timeout := b.config.Timeout
 	if timeout == 0 {
@@ -73,91 +124,36 @@ func (b *Builder) Build(ctx context.Context, opts Options) (*Result, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "buildctl", args...)
-	cmd.Dir = opts.SourcePath
-	cmd.Env = os.Environ()
-
-	installCmd := resolveInstallCommand(opts.BuildConfig.InstallCommand, opts.BuildConfig.Framework)
-	if installCmd != "" {
-		cmd.Env = append(cmd.Env, "RAILPACK_INSTALL_CMD="+installCmd)
-		args = append(args, "--secret", "id=RAILPACK_INSTALL_CMD,env=RAILPACK_INSTALL_CMD")
-		cmd.Args = append(cmd.Args[:1], args...)
-	}
-
-
-	port := "3000"
-	if p, ok := opts.EnvVars["PORT"]; ok && p != "" {
-		port = p
-	}
-	cmd.Env = append(cmd.Env, "PORT="+port)
-	args = append(args, "--secret", "id=PORT,env=PORT")
-	cmd.Args = append(cmd.Args[:1], args...)
-
-	for key, val := range opts.EnvVars {
-		if strings.HasPrefix(key, "RAILPACK_") && val != "" {
-			cmd.Env = append(cmd.Env, key+"="+val)
-			args = append(args, "--secret", "id="+key+",env="+key)
-			cmd.Args = append(cmd.Args[:1], args...)
-		}
-	}
-
 	// ─────────────────────────────────────────────────────────
-	// Step 4: Attach logging
-	// ─────────────────────────────────────────────────────────
-	cmd.Stdout = buildLog
-	cmd.Stderr = buildLog
-
+	// Step 4: Generate Railpack build plan
 	// ─────────────────────────────────────────────────────────
-	// Step 5: Execute build
-	// ─────────────────────────────────────────────────────────
-	// ─────────────────────────────────────────────────────────
-	// Step 5a: Generate Railpack build plan
-	// ─────────────────────────────────────────────────────────
-	buildLog.Log("📋 Generating Railpack build plan...")
-
-	prepareArgs := []string{"prepare", ".", "--plan-out", "railpack-plan.json"}
-	if opts.BuildConfig.BuildCommand != "" {
-		prepareArgs = append(prepareArgs, "--build-cmd", opts.BuildConfig.BuildCommand)
-	}
-	if opts.BuildConfig.RunCommand != "" {
-		prepareArgs = append(prepareArgs, "--start-cmd", opts.BuildConfig.RunCommand)
-	}
-	installCmdForPrepare := resolveInstallCommand(opts.BuildConfig.InstallCommand, opts.BuildConfig.Framework)
-	if installCmdForPrepare != "" {
-		prepareArgs = append(prepareArgs, "--env", "RAILPACK_INSTALL_CMD="+installCmdForPrepare)
-	}
-
-	// Always tell Railpack which port the app will listen on
-	if port, ok := opts.EnvVars["PORT"]; ok && port != "" {
-		prepareArgs = append(prepareArgs, "--env", "PORT="+port)
-	} else {
-		prepareArgs = append(prepareArgs, "--env", "PORT=3000")
-	}
-
-	for key, val := range opts.EnvVars {
-		if strings.HasPrefix(key, "RAILPACK_") && val != "" {
-			prepareArgs = append(prepareArgs, "--env", key+"="+val)
+	var prepareDuration time.Duration
+	if dockerfile == "" {
+		buildLog.Log("📋 Generating Railpack build plan...")
+
+		prepareStart := time.Now()
+		if err := b.backend.Prepare(ctx, prepareRequest(opts, req.Secrets, buildLog)); err != nil {
+			buildLog.Flush()
+			return nil, err
 		}
-	}
-
-	prepareCmd := exec.CommandContext(ctx, "railpack", prepareArgs...)
-	prepareCmd.Dir = opts.SourcePath
-	prepareCmd.Stdout = buildLog
-	prepareCmd.Stderr = buildLog
-	prepareCmd.Env = os.Environ()
+		prepareDuration = time.Since(prepareStart)
 
-	if err := prepareCmd.Run(); err != nil {
-		buildLog.Flush()
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("railpack prepare failed with exit code %d", exitErr.ExitCode())
+		// A Dockerfile brings its own .npmrc and mounts the secrets itself
+		if registry != nil {
+			added, err := addNpmrcToPlan(opts.SourcePath, registry.npmrc)
+			if err != nil {
+				return nil, err
+			}
+			if !added {
+				buildLog.Log("⚠ Build plan has no install step, private npm registries are not configured")
+			}
 		}
-		return nil, fmt.Errorf("railpack prepare failed: %w", err)
-	}
 
-	buildLog.Log("")
+		buildLog.Log("")
+	}
 
 	// ─────────────────────────────────────────────────────────
-	// Step 5b: Build with BuildKit using railpack frontend
+	// Step 5: Build and push
 	// ─────────────────────────────────────────────────────────
 	// Exclude .git from context transfer to reduce size sent to BuildKit
 	b.writeDockerIgnore(opts.SourcePath)
@@ -165,21 +161,17 @@ func (b *Builder) Build(ctx context.Context, opts Options) (*Result, error) {
 	buildLog.Log("🔨 Building image with BuildKit...")
 	buildLog.Log("")
 
-	if err := cmd.Run(); err != nil {
+	output, err := b.backend.Build(ctx, req)
+	if err != nil {
 		buildLog.Flush()
 
 		if ctx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("build timed out after %s", timeout)
+			return nil, fmt.Errorf("build timed out after %s: %w", timeout, context.DeadlineExceeded)
 		}
 		if ctx.Err() == context.Canceled {
 			return nil, fmt.Errorf("build was canceled")
 		}
-
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("build failed with exit code %d", exitErr.ExitCode())
-		}
-
-		return nil, fmt.Errorf("build failed: %w", err)
+		return nil, err
 	}
 
 	duration := time.Since(startTime)
@@ -187,39 +179,68 @@ func (b *Builder) Build(ctx context.Context, opts Options) (*Result, error) {
 	buildLog.Log("")
 	buildLog.Log(fmt.Sprintf("✓ Build completed in %s", duration.Round(time.Second)))
 	buildLog.Log(fmt.Sprintf("✓ Image pushed: %s", opts.ImageName))
+	if output.Digest != "" {
+		buildLog.Log(fmt.Sprintf("✓ Digest: %s", output.Digest))
+	}
 
 	b.logger.Info("Build completed",
 		zap.String("image", opts.ImageName),
+		zap.String("digest", output.Digest),
+		zap.Bool("dockerfile", dockerfile != ""),
+		zap.Bool("cacheUsed", output.CacheUsed),
 		zap.Duration("duration", duration),
 	)
 
 	return &Result{
-		ImageName: opts.ImageName,
-		Duration:  duration,
-		Framework: opts.BuildConfig.Framework,
+		ImageName:       opts.ImageName,
+		Digest:          output.Digest,
+		Duration:        duration,
+		PrepareDuration: prepareDuration,
+		Framework:       opts.BuildConfig.Framework,
+		Dockerfile:      relativePath(opts.SourcePath, dockerfile),
+		CacheUsed:       output.CacheUsed,
 	}, nil
 }
 
-func (b *Builder) buildArgs(opts Options) []string {
-	args := []string{
-		"--addr", b.config.BuildkitAddr,
-		"build",
-		"--frontend", "gateway.v0",
-		"--opt", "source=ghcr.io/railwayapp/railpack-frontend:latest",
-		"--local", "context=" + opts.SourcePath,
-		"--local", "dockerfile=" + opts.SourcePath,
-		"--progress", "plain",
-		"--export-cache", "type=inline",
-		"--import-cache", "type=registry,ref=" + opts.ImageName,
+// railpackRequest describes a Railpack build. The install command, port and
+// the project's build vars reach the plan as secrets, which Railpack exposes
+// to its build steps without writing them into the image.
+func railpackRequest(opts Options) BuildRequest {
+	secrets := make(map[string]string)
+
+	if installCmd := resolveInstallCommand(opts.BuildConfig.InstallCommand, opts.BuildConfig.Framework); installCmd != "" {
+		secrets["RAILPACK_INSTALL_CMD"] = installCmd
 	}
 
-	output := fmt.Sprintf("type=image,name=%s,push=true,compression=uncompressed", opts.ImageName)
-	if b.config.InsecureRegistry {
-		output += ",registry.insecure=true"
+	// Always tell Railpack which port the app will listen on
+	secrets["PORT"] = "3000"
+	if port, ok := opts.EnvVars["PORT"]; ok && port != "" {
+		secrets["PORT"] = port
 	}
-	args = append(args, "--output", output)
 
-	return args
+	for key, val := range opts.BuildEnvVars {
+		if val != "" {
+			secrets[key] = val
+		}
+	}
+
+	return BuildRequest{
+		SourcePath: opts.SourcePath,
+		ImageName:  opts.ImageName,
+		Secrets:    secrets,
+	}
+}
+
+// prepareRequest asks for the plan of a Railpack build; Railpack sees the
+// same settings the build gets as secrets.
+func prepareRequest(opts Options, env map[string]string, buildLog *logging.StreamLogger) PrepareRequest {
+	return PrepareRequest{
+		SourcePath:   opts.SourcePath,
+		BuildCommand: opts.BuildConfig.BuildCommand,
+		StartCommand: opts.BuildConfig.RunCommand,
+		Env:          env,
+		Output:       buildLog,
+	}
 }
 
 // writeDockerIgnore creates or appends to .dockerignore in the source directory
@@ -265,23 +286,3 @@ func resolveInstallCommand(userCmd string, framework string) string {
 	}
 	return userCmd
 }
-
-func sanitizeArgs(args []string) []string {
-	sanitized := make([]string, len(args))
-	copy(sanitized, args)
-
-	for i := 0; i < len(sanitized); i++ {
-		if sanitized[i] == "--opt" && i+1 < len(sanitized) {
-			opt := sanitized[i+1]
-			if strings.HasPrefix(opt, "env:") {
-				if idx := strings.Index(opt, "="); idx != -1 {
-					key := opt[:idx]
-					sanitized[i+1] = key + "=***"
-				}
-			}
-		}
-	}
-
-	return sanitized
-}
-
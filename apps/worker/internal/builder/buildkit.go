@@ -0,0 +1,263 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/logging"
+	"code2cloud/worker/internal/types"
+)
+
+// BuildkitBackend builds with the railpack and buildctl binaries against a
+// remote BuildKit daemon.
+type BuildkitBackend struct {
+	config Config
+	logger *zap.Logger
+}
+
+func NewBuildkitBackend(config Config, logger *zap.Logger) *BuildkitBackend {
+	return &BuildkitBackend{
+		config: config,
+		logger: logger,
+	}
+}
+
+func (k *BuildkitBackend) Name() string {
+	return BackendBuildkit
+}
+
+func (k *BuildkitBackend) Prepare(ctx context.Context, req PrepareRequest) error {
+	args := []string{"prepare", ".", "--plan-out", railpackPlanFile}
+	if req.BuildCommand != "" {
+		args = append(args, "--build-cmd", req.BuildCommand)
+	}
+	if req.StartCommand != "" {
+		args = append(args, "--start-cmd", req.StartCommand)
+	}
+	// Names only: railpack reads each value from its environment, which
+	// keeps secrets out of the process arguments
+	for _, key := range sortedKeys(req.Env) {
+		args = append(args, "--env", key)
+	}
+
+	fmt.Fprintln(req.Output, "$ railpack "+strings.Join(sanitizeArgs(args), " "))
+
+	tail := logging.NewTailBuffer(50)
+
+	cmd := exec.CommandContext(ctx, "railpack", args...)
+	types.KillProcessGroup(cmd)
+	cmd.Dir = req.SourcePath
+	cmd.Stdout = logging.NewMultiWriter(req.Output, tail)
+	cmd.Stderr = cmd.Stdout
+	cmd.Env = os.Environ()
+	for _, key := range sortedKeys(req.Env) {
+		cmd.Env = append(cmd.Env, key+"="+req.Env[key])
+	}
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return &types.CommandError{
+				Command:  "railpack",
+				ExitCode: exitErr.ExitCode(),
+				Output:   tail.Lines(),
+				Err:      fmt.Errorf("railpack prepare failed with exit code %d", exitErr.ExitCode()),
+			}
+		}
+		return fmt.Errorf("railpack prepare failed: %w", err)
+	}
+	return nil
+}
+
+func (k *BuildkitBackend) Build(ctx context.Context, req BuildRequest) (*BuildOutput, error) {
+	args := k.buildArgs(req)
+
+	// buildctl writes the pushed image digest here
+	metadataPath, err := metadataFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build metadata file: %w", err)
+	}
+	defer os.Remove(metadataPath)
+	args = append(args, "--metadata-file", metadataPath)
+
+	fmt.Fprintln(req.Output, "$ buildctl "+strings.Join(sanitizeArgs(args), " "))
+
+	cmd := exec.CommandContext(ctx, "buildctl", args...)
+	types.KillProcessGroup(cmd)
+	cmd.Dir = req.SourcePath
+	cmd.Env = os.Environ()
+	for _, key := range sortedKeys(req.Secrets) {
+		cmd.Env = append(cmd.Env, key+"="+req.Secrets[key])
+	}
+
+	// Keep the tail of buildctl's output to classify failures
+	tail := logging.NewTailBuffer(50)
+	cache := &cacheDetector{}
+	cmd.Stdout = logging.NewMultiWriter(req.Output, tail, cache)
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, classifyBuildError(&types.CommandError{
+				Command:  "buildctl",
+				ExitCode: exitErr.ExitCode(),
+				Output:   tail.Lines(),
+				Err:      fmt.Errorf("build failed with exit code %d", exitErr.ExitCode()),
+			}, tail.String())
+		}
+		return nil, fmt.Errorf("build failed: %w", err)
+	}
+
+	// Without the digest the deploy would fall back to the mutable tag, so
+	// a push that doesn't report one fails the build
+	digest, err := readImageDigest(metadataPath)
+	if err != nil {
+		k.logger.Error("Build pushed but reported no image digest",
+			zap.String("image", req.ImageName),
+			zap.Error(err),
+		)
+		fmt.Fprintf(req.Output, "✗ Image pushed but BuildKit reported no digest: %v\n", err)
+		return nil, fmt.Errorf("failed to read pushed image digest: %w", err)
+	}
+
+	return &BuildOutput{
+		Digest:    digest,
+		CacheUsed: cache.used,
+	}, nil
+}
+
+func (k *BuildkitBackend) HealthCheck(ctx context.Context) error {
+	if err := CheckRailpack(ctx); err != nil {
+		return err
+	}
+	return CheckBuildkitHealth(ctx, k.config.BuildkitAddr, k.logger)
+}
+
+// buildArgs builds the buildctl arguments: the dockerfile.v0 frontend for a
+// Dockerfile, Railpack's gateway frontend reading the prepared plan
+// otherwise. Secret values reach buildctl through its environment.
+func (k *BuildkitBackend) buildArgs(req BuildRequest) []string {
+	args := []string{
+		"--addr", k.config.BuildkitAddr,
+		"build",
+	}
+
+	if req.Dockerfile != "" {
+		args = append(args,
+			"--frontend", "dockerfile.v0",
+			"--local", "context="+req.SourcePath,
+			"--local", "dockerfile="+filepath.Dir(req.Dockerfile),
+			"--opt", "filename="+filepath.Base(req.Dockerfile),
+		)
+		if req.Target != "" {
+			args = append(args, "--opt", "target="+req.Target)
+		}
+		if k.config.Platform != "" {
+			args = append(args, "--opt", "platform="+k.config.Platform)
+		}
+	} else {
+		args = append(args,
+			"--frontend", "gateway.v0",
+			"--opt", "source=ghcr.io/railwayapp/railpack-frontend:latest",
+			"--local", "context="+req.SourcePath,
+			"--local", "dockerfile="+req.SourcePath,
+		)
+	}
+
+	args = append(args,
+		"--progress", "plain",
+		"--export-cache", "type=inline",
+		"--import-cache", "type=registry,ref="+req.ImageName,
+	)
+
+	for _, key := range sortedKeys(req.BuildArgs) {
+		args = append(args, "--opt", "build-arg:"+key+"="+req.BuildArgs[key])
+	}
+	for _, key := range sortedKeys(req.Secrets) {
+		args = append(args, "--secret", "id="+key+",env="+key)
+	}
+
+	output := fmt.Sprintf("type=image,name=%s,push=true,compression=uncompressed", req.ImageName)
+	if k.config.InsecureRegistry {
+		output += ",registry.insecure=true"
+	}
+	args = append(args, "--output", output)
+
+	return args
+}
+
+func metadataFile() (string, error) {
+	f, err := os.CreateTemp("", "buildctl-metadata-*.json")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	return f.Name(), nil
+}
+
+// readImageDigest pulls the pushed image digest out of buildctl's
+// --metadata-file output.
+func readImageDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build metadata: %w", err)
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return "", fmt.Errorf("failed to parse build metadata: %w", err)
+	}
+
+	digest, _ := metadata["containerimage.digest"].(string)
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("build metadata has no image digest")
+	}
+	return digest, nil
+}
+
+// cacheDetector watches buildctl's plain progress output for steps served
+// from the cache ("#7 CACHED").
+type cacheDetector struct {
+	used bool
+}
+
+func (d *cacheDetector) Write(p []byte) (int, error) {
+	if !d.used && bytes.Contains(p, []byte(" CACHED")) {
+		d.used = true
+	}
+	return len(p), nil
+}
+
+func sanitizeArgs(args []string) []string {
+	sanitized := make([]string, len(args))
+	copy(sanitized, args)
+
+	for i := 0; i < len(sanitized); i++ {
+		// railpack prepare --env values are build secrets, should one
+		// ever be passed inline
+		if sanitized[i] == "--env" && i+1 < len(sanitized) {
+			if idx := strings.Index(sanitized[i+1], "="); idx != -1 {
+				sanitized[i+1] = sanitized[i+1][:idx] + "=***"
+			}
+			continue
+		}
+		if sanitized[i] == "--opt" && i+1 < len(sanitized) {
+			opt := sanitized[i+1]
+			if strings.HasPrefix(opt, "env:") || strings.HasPrefix(opt, "build-arg:") {
+				if idx := strings.Index(opt, "="); idx != -1 {
+					key := opt[:idx]
+					sanitized[i+1] = key + "=***"
+				}
+			}
+		}
+	}
+
+	return sanitized
+}
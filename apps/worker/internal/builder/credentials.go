@@ -0,0 +1,207 @@
+package builder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code2cloud/worker/internal/types"
+)
+
+// ---------------------------------------------------------------------------
+// Private registry credentials
+// ---------------------------------------------------------------------------
+// Credentials reach the build only as BuildKit secrets, i.e. env vars of
+// the steps that mount them, never as files in the build context or args:
+//
+//   - npm: NPM_TOKEN (NPM_TOKEN_2, ...) referenced from an npm user config
+//     that holds only ${NPM_TOKEN?} placeholders; Railpack's install step
+//     gets it from the build plan, the repo's .npmrc is never touched
+//   - PyPI: PIP_EXTRA_INDEX_URL / UV_EXTRA_INDEX_URL with the credentials in
+//     the URL
+//   - Go: GOPRIVATE for the module patterns and an Authorization header for
+//     git through GIT_CONFIG_COUNT / GIT_CONFIG_KEY_n / GIT_CONFIG_VALUE_n
+//
+// Railpack exposes every secret to its install and build steps. A
+// Dockerfile mounts the ones it needs:
+//
+//	RUN --mount=type=secret,id=NPM_TOKEN,env=NPM_TOKEN npm ci
+// ---------------------------------------------------------------------------
+
+const (
+	// npm's user config in the build steps, outside /app
+	npmrcPath  = "/root/.npmrc"
+	npmrcAsset = "code2cloud-npmrc"
+)
+
+// registryConfig is what a set of credentials turns into.
+type registryConfig struct {
+	// Build secrets, by env var name
+	secrets map[string]string
+
+	// .npmrc lines; they reference secrets and hold no values themselves
+	npmrc []string
+}
+
+func registrySecrets(credentials []types.RegistryCredential) (*registryConfig, error) {
+	config := &registryConfig{secrets: make(map[string]string)}
+
+	var pipIndexes, goPrivate []string
+	npmCount, gitCount := 0, 0
+
+	for _, cred := range credentials {
+		if cred.Token == "" {
+			continue
+		}
+
+		registryURL, err := url.Parse(strings.TrimSpace(cred.URL))
+		if err != nil || registryURL.Host == "" {
+			return nil, fmt.Errorf("invalid %s registry URL %q", cred.Type, cred.URL)
+		}
+
+		switch cred.Type {
+		case types.RegistryNPM:
+			npmCount++
+			key := "NPM_TOKEN"
+			if npmCount > 1 {
+				key = fmt.Sprintf("NPM_TOKEN_%d", npmCount)
+			}
+
+			registry := strings.TrimSuffix(registryURL.String(), "/") + "/"
+			if cred.Scope != "" {
+				config.npmrc = append(config.npmrc, fmt.Sprintf("%s:registry=%s", cred.Scope, registry))
+			} else {
+				config.npmrc = append(config.npmrc, "registry="+registry)
+			}
+
+			// Auth lines are keyed by the registry URL without its scheme
+			authPrefix := "//" + registryURL.Host + strings.TrimSuffix(registryURL.Path, "/") + "/"
+			if cred.Username != "" {
+				config.secrets[key] = basicAuth(cred.Username, cred.Token)
+				config.npmrc = append(config.npmrc, fmt.Sprintf("%s:_auth=${%s?}", authPrefix, key))
+			} else {
+				config.secrets[key] = cred.Token
+				config.npmrc = append(config.npmrc, fmt.Sprintf("%s:_authToken=${%s?}", authPrefix, key))
+			}
+
+		case types.RegistryPyPI:
+			username := cred.Username
+			if username == "" {
+				username = "__token__"
+			}
+			indexURL := *registryURL
+			indexURL.User = url.UserPassword(username, cred.Token)
+			pipIndexes = append(pipIndexes, indexURL.String())
+
+		case types.RegistryGo:
+			pattern := cred.Scope
+			if pattern == "" {
+				pattern = registryURL.Host
+			}
+			goPrivate = append(goPrivate, pattern)
+
+			username := cred.Username
+			if username == "" {
+				username = "x-access-token"
+			}
+			base := registryURL.Scheme + "://" + registryURL.Host + "/"
+			config.secrets[fmt.Sprintf("GIT_CONFIG_KEY_%d", gitCount)] = "http." + base + ".extraheader"
+			config.secrets[fmt.Sprintf("GIT_CONFIG_VALUE_%d", gitCount)] = "Authorization: Basic " + basicAuth(username, cred.Token)
+			gitCount++
+
+		default:
+			return nil, fmt.Errorf("unknown registry type %q", cred.Type)
+		}
+	}
+
+	// Private indexes are searched next to PyPI, not instead of it
+	if len(pipIndexes) > 0 {
+		config.secrets["PIP_EXTRA_INDEX_URL"] = strings.Join(pipIndexes, " ")
+		config.secrets["UV_EXTRA_INDEX_URL"] = strings.Join(pipIndexes, " ")
+	}
+	if len(goPrivate) > 0 {
+		config.secrets["GOPRIVATE"] = strings.Join(goPrivate, ",")
+		config.secrets["GIT_CONFIG_COUNT"] = fmt.Sprintf("%d", gitCount)
+	}
+
+	return config, nil
+}
+
+// registrySummary describes credentials for the build log, without secrets.
+func registrySummary(credentials []types.RegistryCredential) []string {
+	summary := make([]string, 0, len(credentials))
+	for _, cred := range credentials {
+		host := cred.URL
+		if u, err := url.Parse(cred.URL); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		line := fmt.Sprintf("%s %s", strings.ToLower(string(cred.Type)), host)
+		if cred.Scope != "" {
+			line += " (" + cred.Scope + ")"
+		}
+		summary = append(summary, line)
+	}
+	return summary
+}
+
+// addNpmrcToPlan gives the Railpack install step the registry lines as
+// npm's user config. They go into the plan as a file asset instead of the
+// repo's .npmrc, so the app's own npm config is left alone and the file
+// stays out of the app layer. It reports false when the plan has no
+// install step to add them to.
+func addNpmrcToPlan(sourcePath string, lines []string) (bool, error) {
+	if len(lines) == 0 {
+		return true, nil
+	}
+
+	path := filepath.Join(sourcePath, railpackPlanFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read build plan: %w", err)
+	}
+
+	var plan map[string]interface{}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return false, fmt.Errorf("failed to parse build plan: %w", err)
+	}
+
+	steps, _ := plan["steps"].([]interface{})
+	for _, raw := range steps {
+		step, ok := raw.(map[string]interface{})
+		if !ok || step["name"] != "install" {
+			continue
+		}
+
+		assets, _ := step["assets"].(map[string]interface{})
+		if assets == nil {
+			assets = make(map[string]interface{})
+			step["assets"] = assets
+		}
+		assets[npmrcAsset] = strings.Join(lines, "\n") + "\n"
+
+		// Written before the step's own commands run npm
+		commands, _ := step["commands"].([]interface{})
+		step["commands"] = append([]interface{}{
+			map[string]interface{}{"path": npmrcPath, "name": npmrcAsset},
+		}, commands...)
+
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return false, fmt.Errorf("failed to encode build plan: %w", err)
+		}
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			return false, fmt.Errorf("failed to write build plan: %w", err)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func basicAuth(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
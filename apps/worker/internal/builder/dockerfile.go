@@ -0,0 +1,101 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Dockerfile builds
+// ---------------------------------------------------------------------------
+// A repo that ships a Dockerfile is built with BuildKit's dockerfile.v0
+// frontend instead of Railpack. Env vars are passed as build args, which
+// only reach the build where the Dockerfile declares a matching ARG. Vars
+// the project marks as build secrets are passed as secrets instead, so they
+// never end up in the image history:
+//
+//	RUN --mount=type=secret,id=NPM_TOKEN,env=NPM_TOKEN npm ci
+// ---------------------------------------------------------------------------
+
+const defaultDockerfile = "Dockerfile"
+
+// resolveDockerfile returns the absolute path of the Dockerfile to build
+// with, or "" to fall back to Railpack. A configured path must exist; the
+// default one is optional.
+func resolveDockerfile(sourcePath, configured string) (string, error) {
+	configured = strings.TrimSpace(configured)
+	if configured == "" {
+		path := filepath.Join(sourcePath, defaultDockerfile)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, nil
+		}
+		return "", nil
+	}
+
+	path := filepath.Join(sourcePath, configured)
+	if rel, err := filepath.Rel(sourcePath, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("dockerfile path %q is outside the repository", configured)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("dockerfile not found: %s", configured)
+		}
+		return "", fmt.Errorf("failed to read dockerfile: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("dockerfile path %q is not a file", configured)
+	}
+	return path, nil
+}
+
+// dockerfileRequest describes a Dockerfile build: env vars become build
+// args, the ones the project marked as secrets build secrets.
+func dockerfileRequest(opts Options, dockerfile string) BuildRequest {
+	req := BuildRequest{
+		SourcePath: opts.SourcePath,
+		ImageName:  opts.ImageName,
+		Dockerfile: dockerfile,
+		Target:     opts.BuildConfig.DockerTarget,
+		BuildArgs:  make(map[string]string),
+		Secrets:    make(map[string]string),
+	}
+
+	secret := make(map[string]bool, len(opts.BuildSecrets))
+	for _, key := range opts.BuildSecrets {
+		secret[key] = true
+	}
+
+	for key, val := range MergeEnvVars(opts.EnvVars, opts.BuildEnvVars) {
+		if !secret[key] {
+			req.BuildArgs[key] = val
+		} else if val != "" {
+			req.Secrets[key] = val
+		}
+	}
+	return req
+}
+
+// relativePath returns path relative to base for logs, or "" for "".
+func relativePath(base, path string) string {
+	if path == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
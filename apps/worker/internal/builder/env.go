@@ -25,6 +25,8 @@ var SensitiveEnvKeys = map[string]bool{
 	"REDIS_PASSWORD":    true,
 	"POSTGRES_PASSWORD": true,
 	"MYSQL_PASSWORD":    true,
+	"INDEX_URL":         true,
+	"GIT_CONFIG_VALUE":  true,
 }
 
 func IsSensitiveEnvKey(key string) bool {
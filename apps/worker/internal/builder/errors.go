@@ -0,0 +1,55 @@
+package builder
+
+import (
+	"strings"
+
+	"code2cloud/worker/internal/types"
+)
+
+// transientBuildMarkers are fragments of buildctl output that point at
+// BuildKit or the registry being unavailable, not at the user's code.
+var transientBuildMarkers = []string{
+	"connection refused",
+	"connection reset by peer",
+	"failed to dial",
+	"no such host",
+	"i/o timeout",
+	"tls handshake timeout",
+	"transport is closing",
+	"code = unavailable",
+	"error reading from server: eof",
+	"failed to push",
+	"failed to do request",
+	"500 internal server error",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"toomanyrequests",
+}
+
+// permanentBuildMarkers are fragments of registry auth failures. A push
+// rejected for credentials or permissions fails the same way on every
+// attempt, even though it also reads "failed to push".
+var permanentBuildMarkers = []string{
+	"unauthorized",
+	"denied",
+	"forbidden",
+}
+
+// classifyBuildError marks err transient when buildctl's output shows an
+// infrastructure failure (BuildKit restart, registry hiccup), unless the
+// registry refused the credentials.
+func classifyBuildError(err error, output string) error {
+	lower := strings.ToLower(output)
+	for _, marker := range permanentBuildMarkers {
+		if strings.Contains(lower, marker) {
+			return err
+		}
+	}
+	for _, marker := range transientBuildMarkers {
+		if strings.Contains(lower, marker) {
+			return types.Transient(err)
+		}
+	}
+	return err
+}
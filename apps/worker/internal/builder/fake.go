@@ -0,0 +1,59 @@
+package builder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FakeBackend builds nothing and pushes nothing. It reports a digest
+// derived from the image name and build inputs, so the rest of the
+// pipeline can run without a BuildKit daemon.
+type FakeBackend struct{}
+
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{}
+}
+
+func (f *FakeBackend) Name() string {
+	return BackendFake
+}
+
+// Prepare writes an empty plan where Railpack would.
+func (f *FakeBackend) Prepare(ctx context.Context, req PrepareRequest) error {
+	fmt.Fprintln(req.Output, "fake backend: skipping railpack prepare")
+	return os.WriteFile(filepath.Join(req.SourcePath, railpackPlanFile), []byte("{}\n"), 0644)
+}
+
+func (f *FakeBackend) Build(ctx context.Context, req BuildRequest) (*BuildOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	digest := fakeDigest(req)
+	fmt.Fprintf(req.Output, "fake backend: %s built as %s\n", req.ImageName, digest)
+
+	return &BuildOutput{Digest: digest}, nil
+}
+
+func (f *FakeBackend) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// fakeDigest hashes what would change the image: the same request always
+// gets the same digest.
+func fakeDigest(req BuildRequest) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "image=%s\ndockerfile=%s\ntarget=%s\n",
+		req.ImageName, relativePath(req.SourcePath, req.Dockerfile), req.Target)
+	for _, key := range sortedKeys(req.BuildArgs) {
+		fmt.Fprintf(h, "arg:%s=%s\n", key, req.BuildArgs[key])
+	}
+	for _, key := range sortedKeys(req.Secrets) {
+		fmt.Fprintf(h, "secret:%s\n", key)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
@@ -1,6 +1,11 @@
 package builder
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"code2cloud/worker/internal/types"
+)
 
 
 type Config struct {
@@ -46,11 +51,20 @@ type Options struct {
 	// Build configuration from job
 	BuildConfig BuildConfigOptions
 
-	// Environment variables to bake into image
+	// Builder defaults (CI, NODE_ENV, framework settings, PORT)
 	EnvVars map[string]string
 
-	// Build-time only env vars
+	// Private package registries the build installs from; passed as build
+	// secrets only
+	RegistryCredentials []types.RegistryCredential
+
+	// The project's build env vars. Railpack gets them as build secrets; a
+	// Dockerfile gets them as build args, or as secrets when listed in
+	// BuildSecrets.
 	BuildEnvVars map[string]string
+
+	// Keys of BuildEnvVars the project marked as build secrets
+	BuildSecrets []string
 }
 
 type BuildConfigOptions struct {
@@ -59,6 +73,13 @@ type BuildConfigOptions struct {
 	RunCommand     string
 	OutputDir      string
 	Framework      string
+
+	// Dockerfile to build with, relative to SourcePath. When empty a
+	// Dockerfile at the root is used if there is one, Railpack otherwise.
+	DockerfilePath string
+
+	// Dockerfile stage to build (--target); the last stage when empty
+	DockerTarget string
 }
 
 type Result struct {
@@ -68,16 +89,38 @@ type Result struct {
 	// Image digest (sha256:...)
 	Digest string
 
-	// Build duration
+	// Build duration (including plan generation)
 	Duration time.Duration
 
+	// Time spent in `railpack prepare`
+	PrepareDuration time.Duration
+
 	// Detected framework (if auto-detected)
 	Framework string
 
-	// Whether cache was used
+	// Dockerfile the image was built from, relative to the source path;
+	// empty for Railpack builds
+	Dockerfile string
+
+	// Whether any build step was served from the cache
 	CacheUsed bool
 }
 
+// ImageRef is the reference to deploy: the image pinned to its digest when
+// buildctl reported one, so a rebuilt tag can't change what runs.
+func (r *Result) ImageRef() string {
+	return PinnedImage(r.ImageName, r.Digest)
+}
+
+// PinnedImage returns image@digest, or image unchanged when digest is empty
+// or the image is already pinned.
+func PinnedImage(image, digest string) string {
+	if digest == "" || strings.Contains(image, "@") {
+		return image
+	}
+	return image + "@" + digest
+}
+
 type Framework string
 
 const (
@@ -82,6 +82,14 @@ func VerifyBuildctl(ctx context.Context, logger *zap.Logger) (bool, string) {
 }
 
 
+// CheckRailpack verifies the railpack binary is still on PATH.
+func CheckRailpack(ctx context.Context) error {
+	if _, err := exec.LookPath("railpack"); err != nil {
+		return fmt.Errorf("railpack not found: %w", err)
+	}
+	return nil
+}
+
 func CheckBuildkitHealth(ctx context.Context, addr string, logger *zap.Logger) error {
 	_, err := exec.LookPath("buildctl")
 	if err != nil {
@@ -104,6 +112,6 @@ func CheckBuildkitHealth(ctx context.Context, addr string, logger *zap.Logger) e
 		return fmt.Errorf("buildkit not reachable at %s: %w", addr, err)
 	}
 
-	logger.Info("BuildKit health check passed", zap.String("addr", addr))
+	logger.Debug("BuildKit health check passed", zap.String("addr", addr))
 	return nil
 }
\ No newline at end of file
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,6 +21,10 @@ type Config struct {
 	// ─── BuildKit ────────────────────────────────────────────
 	BuildkitAddr string
 
+	// Build backend: "buildkit" (railpack + buildctl) or "fake", which
+	// builds and pushes nothing, for running the pipeline without BuildKit
+	BuildBackend string
+
 	// ─── Registry ────────────────────────────────────────────
 	RegistryURL      string
 	RegistryInsecure bool
@@ -31,6 +36,20 @@ type Config struct {
 	// ─── Kubernetes ──────────────────────────────────────────
 	Namespace string
 
+	// How long the previous color of a blue/green app keeps running
+	BlueGreenGracePeriod time.Duration
+
+	// Canary: traffic percentages tried before promotion, how long each
+	// is watched, and the 5xx ratio that aborts the rollout
+	CanarySteps        []int
+	CanaryStepInterval time.Duration
+	CanaryMaxErrorRate float64
+
+	// Scale to zero: where the activator that wakes sleeping apps listens,
+	// and how long a woken app's pods may take to become ready
+	ActivatorAddr string
+	WakeTimeout   time.Duration
+
 	// ─── Domain ──────────────────────────────────────────────
 	ServerIP string
 	BaseDomain string
@@ -40,6 +59,16 @@ type Config struct {
 	WorkerID        string
 	ConcurrentJobs  int
 	WorkspacePath   string
+	ShutdownTimeout time.Duration
+
+	// ─── Observability ───────────────────────────────────────
+	MetricsAddr         string
+	HealthAddr          string
+	HealthCheckInterval time.Duration
+
+	// ─── Retries ─────────────────────────────────────────────
+	MaxJobAttempts int
+	RetryBaseDelay time.Duration
 }
 
 func Load() (*Config, error) {
@@ -49,17 +78,37 @@ func Load() (*Config, error) {
 		WorkerAPIKey:    getEnv("WORKER_API_KEY", ""),
 		RedisURL:        getEnv("REDIS_URL", "redis://redis.code2cloud.svc.cluster.local:6379"),
 		BuildkitAddr:    getEnv("BUILDKIT_ADDR", "tcp://buildkitd.default.svc.cluster.local:1234"),
+		BuildBackend:    getEnv("BUILD_BACKEND", "buildkit"),
 		RegistryURL:     getEnv("REGISTRY_URL", "registry.registry.svc.cluster.local:5000"),
 		RegistryInsecure: getEnv("REGISTRY_INSECURE", "true") == "true",
 		BuildTimeout:    getDurationEnv("BUILD_TIMEOUT", 15*time.Minute),
 		BuildPlatform:   getEnv("BUILD_PLATFORM", ""),
 		Namespace:       getEnv("K8S_NAMESPACE", "deployments"),
+		BlueGreenGracePeriod: getDurationEnv("BLUE_GREEN_GRACE_PERIOD", 10*time.Minute),
+		CanarySteps:        getIntListEnv("CANARY_STEPS", []int{10, 25, 50}),
+		CanaryStepInterval: getDurationEnv("CANARY_STEP_INTERVAL", time.Minute),
+		CanaryMaxErrorRate: getFloatEnv("CANARY_MAX_ERROR_RATE", 0.05),
+		ActivatorAddr:      getEnv("ACTIVATOR_ADDR", ":8082"),
+		WakeTimeout:        getDurationEnv("WAKE_TIMEOUT", 2*time.Minute),
 		ServerIP:        getEnv("SERVER_IP", ""),
 		BaseDomain:      getEnv("BASE_DOMAIN", "code2cloud.lakshman.me"),
 		QueueName:       getEnv("QUEUE_NAME", "build-queue"),
 		WorkerID:        getEnv("WORKER_ID", "worker-1"),
 		ConcurrentJobs:  getIntEnv("CONCURRENT_JOBS", 1),
 		WorkspacePath:   getEnv("WORKSPACE_PATH", "/tmp/builds"),
+		ShutdownTimeout: getDurationEnv("SHUTDOWN_TIMEOUT", 10*time.Minute),
+		MetricsAddr:     getEnv("METRICS_ADDR", ":9090"),
+		HealthAddr:      getEnv("HEALTH_ADDR", ":8081"),
+		HealthCheckInterval: getDurationEnv("HEALTH_CHECK_INTERVAL", 15*time.Second),
+		MaxJobAttempts:  getIntEnv("JOB_MAX_ATTEMPTS", 3),
+		RetryBaseDelay:  getDurationEnv("JOB_RETRY_BASE_DELAY", 15*time.Second),
+	}
+
+	if cfg.ConcurrentJobs < 1 {
+		cfg.ConcurrentJobs = 1
+	}
+	if cfg.MaxJobAttempts < 1 {
+		cfg.MaxJobAttempts = 1
 	}
 
 	// Validate required fields
@@ -86,6 +135,33 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getIntListEnv parses a comma-separated list such as "10,25,50".
+func getIntListEnv(key string, defaultValue []int) []int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []int
+	for _, part := range strings.Split(value, ",") {
+		intVal, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return defaultValue
+		}
+		list = append(list, intVal)
+	}
+	return list
+}
+
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
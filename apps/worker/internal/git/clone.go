@@ -12,6 +12,7 @@ import (
 	"go.uber.org/zap"
 
 	"code2cloud/worker/internal/logging"
+	"code2cloud/worker/internal/types"
 )
 
 type Cloner struct {
@@ -109,13 +110,16 @@ func (c *Cloner) Clone(ctx context.Context, opts CloneOptions) (*CloneResult, er
 	// Step 4: Execute git clone with live logging
 	// ─────────────────────────────────────────────────────────
 	cmd := exec.CommandContext(ctx, "git", args...)
+	types.KillProcessGroup(cmd)
 
 	// Set environment for git
 	cmd.Env = append(os.Environ(),
 		"GIT_TERMINAL_PROMPT=0", // Never prompt for credentials
 	)
 	
-	filteredWriter := NewProgressFilter(streamLogger)
+	// Keep the tail of git's output to classify failures
+	tail := logging.NewTailBuffer(20)
+	filteredWriter := NewProgressFilter(logging.NewMultiWriter(streamLogger, tail))
 	cmd.Stdout = filteredWriter
 	cmd.Stderr = filteredWriter
 
@@ -126,7 +130,19 @@ func (c *Cloner) Clone(ctx context.Context, opts CloneOptions) (*CloneResult, er
 	// Run the command
 	if err := cmd.Run(); err != nil {
 		streamLogger.Flush()
-		return nil, fmt.Errorf("git clone failed: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("git clone interrupted: %w", ctx.Err())
+		}
+		cmdErr := &types.CommandError{
+			Command:  "git",
+			ExitCode: -1,
+			Output:   tail.Lines(),
+			Err:      fmt.Errorf("git clone failed: %w", err),
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			cmdErr.ExitCode = exitErr.ExitCode()
+		}
+		return nil, classifyCloneError(cmdErr, tail.String())
 	}
 
 	// ─────────────────────────────────────────────────────────
@@ -209,6 +225,7 @@ func (c *Cloner) sanitizeURL(url string) string {
 // checkout checks out a specific commit
 func (c *Cloner) checkout(ctx context.Context, repoPath, commit string, streamLogger *logging.StreamLogger) error {
 	cmd := exec.CommandContext(ctx, "git", "checkout", commit)
+	types.KillProcessGroup(cmd)
 	cmd.Dir = repoPath
 	filteredWriter := NewProgressFilter(streamLogger)
 	cmd.Stdout = filteredWriter
@@ -0,0 +1,37 @@
+package git
+
+import (
+	"strings"
+
+	"code2cloud/worker/internal/types"
+)
+
+// transientGitMarkers are fragments of git output that point at the network
+// or the git host rather than a bad repo, branch or credentials.
+var transientGitMarkers = []string{
+	"could not resolve host",
+	"connection timed out",
+	"connection reset",
+	"connection refused",
+	"operation timed out",
+	"early eof",
+	"rpc failed",
+	"the remote end hung up unexpectedly",
+	"temporary failure",
+	"internal server error",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+}
+
+// classifyCloneError marks err transient when git's output shows a
+// network or server-side failure.
+func classifyCloneError(err error, output string) error {
+	lower := strings.ToLower(output)
+	for _, marker := range transientGitMarkers {
+		if strings.Contains(lower, marker) {
+			return types.Transient(err)
+		}
+	}
+	return err
+}
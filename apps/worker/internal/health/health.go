@@ -0,0 +1,216 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CheckFunc reports a dependency or internal condition as healthy (nil) or
+// not (error).
+type CheckFunc func(ctx context.Context) error
+
+// Result is the last outcome of a single check.
+type Result struct {
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Checker runs readiness checks in the background and caches the results so
+// probes never block on a slow dependency. Liveness checks are cheap and run
+// on every probe.
+type Checker struct {
+	logger *zap.Logger
+
+	liveness  map[string]CheckFunc
+	readiness map[string]CheckFunc
+
+	interval time.Duration
+	timeout  time.Duration
+
+	mu      sync.RWMutex
+	results map[string]Result
+
+	wg sync.WaitGroup
+}
+
+type Config struct {
+	Logger *zap.Logger
+
+	// Liveness checks fail only when the process is wedged and should be
+	// restarted.
+	Liveness map[string]CheckFunc
+
+	// Readiness checks fail while a dependency is down; the worker stops
+	// taking jobs until they recover.
+	Readiness map[string]CheckFunc
+
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+func New(config Config) *Checker {
+	interval := config.Interval
+	if interval == 0 {
+		interval = 15 * time.Second
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &Checker{
+		logger:    config.Logger,
+		liveness:  config.Liveness,
+		readiness: config.Readiness,
+		interval:  interval,
+		timeout:   timeout,
+		results:   make(map[string]Result),
+	}
+}
+
+// Start runs every readiness check once before returning, then keeps
+// refreshing them until ctx is cancelled.
+func (c *Checker) Start(ctx context.Context) {
+	c.refresh(ctx)
+
+	c.wg.Add(1)
+
+	go func() {
+		defer c.wg.Done()
+
+		c.logger.Info("Health checker started",
+			zap.Duration("check_interval", c.interval),
+			zap.Strings("readiness_checks", names(c.readiness)),
+		)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				c.logger.Info("Health checker stopped")
+				return
+			case <-ticker.C:
+				c.refresh(ctx)
+			}
+		}
+	}()
+}
+
+func (c *Checker) Stop() {
+	c.wg.Wait()
+}
+
+// Ready reports whether every readiness check passed on its last run.
+func (c *Checker) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for name := range c.readiness {
+		if !c.results[name].Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Readiness returns the cached readiness results.
+func (c *Checker) Readiness() (bool, map[string]Result) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ready := true
+	results := make(map[string]Result, len(c.readiness))
+	for name := range c.readiness {
+		result := c.results[name]
+		if !result.Healthy {
+			ready = false
+			if result.CheckedAt.IsZero() {
+				result.Error = "not checked yet"
+			}
+		}
+		results[name] = result
+	}
+	return ready, results
+}
+
+// Liveness runs the liveness checks now.
+func (c *Checker) Liveness(ctx context.Context) (bool, map[string]Result) {
+	alive := true
+	results := make(map[string]Result, len(c.liveness))
+	for name, check := range c.liveness {
+		result := c.run(ctx, check)
+		if !result.Healthy {
+			alive = false
+		}
+		results[name] = result
+	}
+	return alive, results
+}
+
+func (c *Checker) refresh(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for name, check := range c.readiness {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+
+			result := c.run(ctx, check)
+			c.record(name, result)
+		}(name, check)
+	}
+	wg.Wait()
+}
+
+func (c *Checker) run(ctx context.Context, check CheckFunc) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	result := Result{Healthy: true, CheckedAt: time.Now()}
+	if err := check(ctx); err != nil {
+		result.Healthy = false
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// record stores a result and logs transitions, not every check.
+func (c *Checker) record(name string, result Result) {
+	c.mu.Lock()
+	previous, seen := c.results[name]
+	c.results[name] = result
+	c.mu.Unlock()
+
+	if seen && previous.Healthy == result.Healthy {
+		return
+	}
+
+	if result.Healthy {
+		c.logger.Info("Health check passing", zap.String("check", name))
+	} else {
+		c.logger.Warn("Health check failing",
+			zap.String("check", name),
+			zap.String("error", result.Error),
+		)
+	}
+}
+
+func names(checks map[string]CheckFunc) []string {
+	out := make([]string, 0, len(checks))
+	for name := range checks {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Handler serves /livez and /readyz. Both return 200 when healthy and 503
+// otherwise, with the per-check results as JSON.
+func (c *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		alive, results := c.Liveness(r.Context())
+		writeResponse(w, alive, results)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ready, results := c.Readiness()
+		writeResponse(w, ready, results)
+	})
+
+	return mux
+}
+
+func writeResponse(w http.ResponseWriter, healthy bool, results map[string]Result) {
+	body := response{Status: "ok", Checks: results}
+	code := http.StatusOK
+	if !healthy {
+		body.Status = "unhealthy"
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Serve exposes the checker's probes on addr until ctx is cancelled. It
+// returns once the listener is shut down.
+func Serve(ctx context.Context, addr string, checker *Checker, logger *zap.Logger) {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           checker.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Health server listening", zap.String("addr", addr))
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Health server failed", zap.Error(err))
+	}
+}
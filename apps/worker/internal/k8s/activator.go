@@ -0,0 +1,203 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Activator receives the requests of sleeping apps. It wakes the app the
+// request's host belongs to, holds the request until the pods are ready and
+// then proxies it, so the first visitor gets a slow response instead of an
+// error.
+type Activator struct {
+	client      *Client
+	logStreamer *LogStreamer
+	logger      *zap.Logger
+
+	markAwake func(ctx context.Context, deploymentID string) error
+
+	wakeTimeout time.Duration
+
+	// Context wakes run on; set by Serve so a visitor who gives up
+	// doesn't abort a wake others are waiting for
+	ctx context.Context
+
+	mu     sync.Mutex
+	waking map[string]*wakeCall
+}
+
+type ActivatorConfig struct {
+	Client      *Client
+	LogStreamer *LogStreamer
+	Logger      *zap.Logger
+
+	// Reports the woken deployment as READY again
+	MarkAwake func(ctx context.Context, deploymentID string) error
+
+	// How long pods may take to become ready. Defaults to 2 minutes.
+	WakeTimeout time.Duration
+}
+
+// wakeCall is a wake in progress, shared by every request that arrives
+// for the app while it runs.
+type wakeCall struct {
+	done chan struct{}
+	err  error
+}
+
+func NewActivator(config ActivatorConfig) *Activator {
+	timeout := config.WakeTimeout
+	if timeout == 0 {
+		timeout = 2 * time.Minute
+	}
+
+	return &Activator{
+		client:      config.Client,
+		logStreamer: config.LogStreamer,
+		logger:      config.Logger,
+		markAwake:   config.MarkAwake,
+		wakeTimeout: timeout,
+		ctx:         context.Background(),
+		waking:      make(map[string]*wakeCall),
+	}
+}
+
+func (a *Activator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	appName, asleep, err := a.client.appForHost(r.Context(), r.Host)
+	if err != nil {
+		a.logger.Warn("Failed to look up app for host",
+			zap.String("host", r.Host),
+			zap.Error(err),
+		)
+		http.Error(w, "Service temporarily unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if appName == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Requests can still arrive here briefly after a wake; those are
+	// simply passed on
+	if asleep {
+		if err := a.wake(r.Context(), appName); err != nil {
+			if r.Context().Err() == nil {
+				a.logger.Warn("Failed to wake app",
+					zap.String("app", appName),
+					zap.Error(err),
+				)
+				w.Header().Set("Retry-After", "10")
+				http.Error(w, "Application is starting, please retry shortly", http.StatusServiceUnavailable)
+			}
+			return
+		}
+	}
+
+	target := &url.URL{
+		Scheme: "http",
+		Host:   fmt.Sprintf("%s.%s.svc.cluster.local", appName, a.client.namespace),
+	}
+	httputil.NewSingleHostReverseProxy(target).ServeHTTP(w, r)
+}
+
+// wake wakes appName once however many requests are waiting for it.
+func (a *Activator) wake(ctx context.Context, appName string) error {
+	a.mu.Lock()
+	call, inFlight := a.waking[appName]
+	if !inFlight {
+		call = &wakeCall{done: make(chan struct{})}
+		a.waking[appName] = call
+
+		go func() {
+			call.err = a.wakeApp(a.ctx, appName)
+
+			a.mu.Lock()
+			delete(a.waking, appName)
+			a.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *Activator) wakeApp(ctx context.Context, appName string) error {
+	start := time.Now()
+
+	deploymentID, err := a.client.Wake(ctx, appName, a.wakeTimeout)
+	if err != nil {
+		return err
+	}
+	if deploymentID == "" {
+		return nil
+	}
+
+	a.logger.Info("Woke up sleeping app ⏰",
+		zap.String("app", appName),
+		zap.String("deployment", deploymentID),
+		zap.Duration("duration", time.Since(start)),
+	)
+
+	systemLog := a.client.logFactory.CreateSystemLogger(deploymentID)
+	systemLog.Log(fmt.Sprintf("⏰ Woken up by an incoming request (ready in %s)",
+		time.Since(start).Round(100*time.Millisecond)))
+	systemLog.Close()
+
+	if a.logStreamer != nil {
+		a.logStreamer.WatchEvents(ctx, deploymentID, appName)
+		if err := a.logStreamer.StartStreaming(ctx, deploymentID, appName); err != nil {
+			a.logger.Warn("Failed to start runtime log streaming (non-fatal)",
+				zap.String("deployment", deploymentID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if err := a.markAwake(ctx, deploymentID); err != nil {
+		a.logger.Warn("Failed to mark deployment as awake",
+			zap.String("deployment", deploymentID),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+// Serve listens on addr until ctx is cancelled. Wakes run on ctx, so they
+// outlive the request that started them.
+func (a *Activator) Serve(ctx context.Context, addr string) {
+	a.ctx = ctx
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           a,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	a.logger.Info("Activator listening", zap.String("addr", addr))
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		a.logger.Error("Activator failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,179 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"code2cloud/worker/internal/types"
+)
+
+// ---------------------------------------------------------------------------
+// Horizontal autoscaling
+// ---------------------------------------------------------------------------
+// Each Deployment of an autoscaled app (including both blue/green colors)
+// gets a HorizontalPodAutoscaler of the same name, so the autoscaler always
+// follows the Deployment it scales and is removed with it.
+// ---------------------------------------------------------------------------
+
+// defaultTargetCPUPercent applies when autoscaling is enabled without targets
+const defaultTargetCPUPercent = 80
+
+// autoscaled reports whether an autoscaler manages the replica count.
+func (o DeployOptions) autoscaled() bool {
+	return o.Scaling.MaxReplicas > o.Replicas
+}
+
+// initialReplicas keeps the replica count an autoscaler already chose, so
+// redeploying doesn't drop a scaled-up app back to its minimum.
+func (o DeployOptions) initialReplicas(current *int32) int32 {
+	if !o.autoscaled() || current == nil {
+		return o.Replicas
+	}
+	switch {
+	case *current < o.Replicas:
+		return o.Replicas
+	case *current > o.Scaling.MaxReplicas:
+		return o.Scaling.MaxReplicas
+	default:
+		return *current
+	}
+}
+
+// applyAutoscaler creates or updates the HorizontalPodAutoscaler of
+// deploymentName, or removes it when the app runs a fixed replica count.
+func (c *Client) applyAutoscaler(ctx context.Context, opts DeployOptions, deploymentName string) error {
+	if !opts.autoscaled() {
+		return c.DeleteAutoscaler(ctx, deploymentName)
+	}
+
+	name := opts.ResourceName()
+
+	labels := map[string]string{
+		"app":                          name,
+		"app.kubernetes.io/managed-by": "code2cloud",
+		"code2cloud/deployment-id":     opts.DeploymentID,
+		"code2cloud/project-id":        opts.ProjectID,
+	}
+	for k, v := range opts.environmentLabels() {
+		labels[k] = v
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentName,
+			Namespace: c.namespace,
+			Labels:    labels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deploymentName,
+			},
+			MinReplicas: int32Ptr(opts.Replicas),
+			MaxReplicas: opts.Scaling.MaxReplicas,
+			Metrics:     autoscalerMetrics(opts.Scaling.TargetCPUPercent, opts.Scaling.TargetMemoryPercent),
+		},
+	}
+
+	c.logger.Info("Creating/updating autoscaler",
+		zap.String("name", deploymentName),
+		zap.Int32("min", opts.Replicas),
+		zap.Int32("max", opts.Scaling.MaxReplicas),
+	)
+
+	hpaClient := c.clientset.AutoscalingV2().HorizontalPodAutoscalers(c.namespace)
+
+	existing, err := hpaClient.Get(ctx, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if _, err := hpaClient.Create(ctx, hpa, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create autoscaler: %w", err)
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to get autoscaler: %w", err)
+	}
+
+	hpa.ResourceVersion = existing.ResourceVersion
+	if _, err := hpaClient.Update(ctx, hpa, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update autoscaler: %w", err)
+	}
+	return nil
+}
+
+func autoscalerMetrics(cpuPercent, memoryPercent int32) []autoscalingv2.MetricSpec {
+	if cpuPercent == 0 && memoryPercent == 0 {
+		cpuPercent = defaultTargetCPUPercent
+	}
+
+	targets := []struct {
+		resource corev1.ResourceName
+		percent  int32
+	}{
+		{corev1.ResourceCPU, cpuPercent},
+		{corev1.ResourceMemory, memoryPercent},
+	}
+
+	var metrics []autoscalingv2.MetricSpec
+	for _, target := range targets {
+		if target.percent == 0 {
+			continue
+		}
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: target.resource,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: int32Ptr(target.percent),
+				},
+			},
+		})
+	}
+	return metrics
+}
+
+func (c *Client) DeleteAutoscaler(ctx context.Context, name string) error {
+	err := c.clientset.AutoscalingV2().HorizontalPodAutoscalers(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete autoscaler: %w", err)
+	}
+	return nil
+}
+
+// ReplicaCounts returns the replicas of every deployment the worker manages.
+func (c *Client) ReplicaCounts(ctx context.Context) ([]types.ReplicaCount, error) {
+	deployments, err := c.clientset.AppsV1().Deployments(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/managed-by=code2cloud",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	counts := make([]types.ReplicaCount, 0, len(deployments.Items))
+	for _, deployment := range deployments.Items {
+		deploymentID := deployment.Spec.Template.Labels["code2cloud/deployment-id"]
+		if deploymentID == "" {
+			continue
+		}
+
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+
+		counts = append(counts, types.ReplicaCount{
+			DeploymentID:    deploymentID,
+			CurrentReplicas: deployment.Status.ReadyReplicas,
+			DesiredReplicas: desired,
+		})
+	}
+	return counts, nil
+}
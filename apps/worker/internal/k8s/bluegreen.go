@@ -0,0 +1,409 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"code2cloud/worker/internal/logging"
+	"code2cloud/worker/internal/types"
+)
+
+// ---------------------------------------------------------------------------
+// Blue/green deploys
+// ---------------------------------------------------------------------------
+// A blue/green app runs two Deployments, <name>-blue and <name>-green, whose
+// pods share the "app" label and differ only in "code2cloud/color". The
+// Service selects one color at a time. A deploy updates the idle color,
+// waits for it to be ready and answer HTTP, then swaps the Service selector
+// in a single update. The previous color keeps running for the grace period
+// so traffic can be switched back without waiting for pods, after which
+// RetireDeployments removes it. An app coming from a rolling Deployment
+// has its Service pinned to that Deployment's pods first.
+// ---------------------------------------------------------------------------
+
+const (
+	colorLabel            = "code2cloud/color"
+	retireAfterAnnotation = "code2cloud/retire-after"
+
+	colorBlue  = "blue"
+	colorGreen = "green"
+)
+
+func (c *Client) deployBlueGreen(ctx context.Context, opts DeployOptions, deployLog *logging.StreamLogger) (*DeployResult, error) {
+	name := opts.ResourceName()
+
+	applyStart := time.Now()
+
+	active, err := c.activeColor(ctx, name)
+	if err != nil {
+		return nil, classifyAPIError(err)
+	}
+	next := otherColor(active)
+	nextName := colorDeploymentName(name, next)
+
+	deployLog.Log(fmt.Sprintf("Deploying %s to Kubernetes (blue/green)...", name))
+	if active != "" {
+		deployLog.Log(fmt.Sprintf("%s is live, deploying %s alongside it", active, next))
+	}
+
+	if err := c.applyIsolation(ctx, opts, deployLog); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.pinLivePods(ctx, opts, active, deployLog); err != nil {
+		return nil, classifyAPIError(err)
+	}
+
+	deployLog.Log(fmt.Sprintf("Creating %s deployment...", next))
+
+	if err := c.applyDeployment(ctx, opts, nextName, map[string]string{colorLabel: next}); err != nil {
+		return nil, classifyAPIError(fmt.Errorf("failed to create deployment: %w", err))
+	}
+
+	deployLog.Log(fmt.Sprintf("✓ Deployment %s created", nextName))
+
+	applyDuration := time.Since(applyStart)
+
+	// ── Verify the new color before it gets any traffic ──
+	deployLog.Log(fmt.Sprintf("Waiting for %s pods to be ready...", next))
+
+	readyStart := time.Now()
+
+	if err := c.WaitForDeploymentReady(ctx, nextName, 5*time.Minute); err != nil {
+		logNotReady(deployLog, err)
+		c.abandonColor(ctx, nextName, deployLog)
+		return nil, fmt.Errorf("%s never became ready, traffic stays on the previous version: %w", nextName, err)
+	}
+
+	deployLog.Log("✓ Pods are ready")
+
+	selector := fmt.Sprintf("app=%s,%s=%s", name, colorLabel, next)
+	if err := c.verifyRollout(ctx, opts, nextName, selector, deployLog); err != nil {
+		c.abandonColor(ctx, nextName, deployLog)
+		return nil, fmt.Errorf("%s failed its health check, traffic stays on the previous version: %w", nextName, err)
+	}
+
+	readyDuration := time.Since(readyStart)
+
+	// ── Switch traffic ──
+	deployLog.Log(fmt.Sprintf("Switching traffic to %s...", next))
+
+	if err := c.applyService(ctx, opts, map[string]string{"app": name, colorLabel: next}); err != nil {
+		return nil, classifyAPIError(fmt.Errorf("failed to switch service: %w", err))
+	}
+
+	deployLog.Log(fmt.Sprintf("✓ Service %s now routes to %s (port 80 → %d)", name, next, opts.Port))
+
+	c.dropTrafficSplit(ctx, name)
+
+	hosts, err := c.applyIngress(ctx, opts, deployLog)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the previous version warm for a fast switch back. Before the
+	// first blue/green deploy that's the rolling Deployment.
+	previous := name
+	if active != "" {
+		previous = colorDeploymentName(name, active)
+	}
+
+	scheduled, err := c.scheduleRetirement(ctx, previous)
+	if err != nil {
+		c.logger.Warn("Failed to schedule retirement of previous color",
+			zap.String("deployment", previous),
+			zap.Error(err),
+		)
+	} else if scheduled {
+		deployLog.Log(fmt.Sprintf("Previous version %s kept for %s", previous, c.blueGreenGrace))
+	}
+
+	result := newDeployResult(name, nextName, hosts, applyDuration, readyDuration)
+
+	c.logger.Info("Blue/green deployment complete",
+		zap.String("name", name),
+		zap.String("color", next),
+		zap.Strings("urls", result.URLs),
+	)
+
+	return result, nil
+}
+
+// activeColor returns the color the app's Service routes to, or "" when the
+// Service doesn't exist yet or still selects a rolling Deployment.
+func (c *Client) activeColor(ctx context.Context, name string) (string, error) {
+	service, err := c.clientset.CoreV1().Services(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get service: %w", err)
+	}
+	return service.Spec.Selector[colorLabel], nil
+}
+
+// liveSelector returns a Service selector matching only the live version's
+// pods, or nil when nothing is live. A rolling Deployment's pods share the
+// "app" label with both colors, so they are matched by deployment id.
+func (c *Client) liveSelector(ctx context.Context, name, active string) (map[string]string, error) {
+	if active != "" {
+		return map[string]string{"app": name, colorLabel: active}, nil
+	}
+
+	rolling, err := c.getDeployment(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if rolling == nil {
+		return nil, nil
+	}
+
+	id := rolling.Spec.Template.Labels["code2cloud/deployment-id"]
+	if id == "" {
+		return nil, nil
+	}
+	return map[string]string{"app": name, "code2cloud/deployment-id": id}, nil
+}
+
+// pinLivePods narrows the app's Service to the live pods before a new color
+// is created. Before the first blue/green or canary deploy the Service
+// selects on "app" alone, which would also send traffic to the new pods as
+// soon as they are ready, before they are verified. It returns the
+// selector, or nil when nothing is live.
+func (c *Client) pinLivePods(ctx context.Context, opts DeployOptions, active string, deployLog *logging.StreamLogger) (map[string]string, error) {
+	name := opts.ResourceName()
+
+	selector, err := c.liveSelector(ctx, name, active)
+	if err != nil || selector == nil {
+		return nil, err
+	}
+
+	service, err := c.clientset.CoreV1().Services(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return selector, nil
+		}
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if labels.Equals(selector, service.Spec.Selector) {
+		return selector, nil
+	}
+
+	if err := c.applyService(ctx, opts, selector); err != nil {
+		return nil, fmt.Errorf("failed to pin service: %w", err)
+	}
+
+	deployLog.Log(fmt.Sprintf("Service %s pinned to the live pods until the switch", name))
+	return selector, nil
+}
+
+// abandonColor removes a color that failed verification. The Service never
+// pointed at it, so nothing that serves traffic changes.
+func (c *Client) abandonColor(ctx context.Context, deploymentName string, deployLog *logging.StreamLogger) {
+	deployLog.Log(fmt.Sprintf("✗ Removing %s, the previous version keeps serving", deploymentName))
+
+	if err := c.DeleteDeployment(context.WithoutCancel(ctx), deploymentName); err != nil {
+		c.logger.Warn("Failed to remove unverified deployment",
+			zap.String("deployment", deploymentName),
+			zap.Error(err),
+		)
+	}
+}
+
+// verifyRollout checks the new pods over HTTP before they take traffic and
+// turns a failure into a *ReadinessError for the build log and failure
+// record.
+func (c *Client) verifyRollout(ctx context.Context, opts DeployOptions, deploymentName, selector string, deployLog *logging.StreamLogger) error {
+	path := healthCheckPath(opts.HealthCheck)
+	deployLog.Log(fmt.Sprintf("Verifying HTTP health on %s...", path))
+
+	if err := c.verifyHTTPHealth(ctx, selector, opts.Port, opts.HealthCheck, time.Minute); err != nil {
+		failure := &ReadinessError{
+			Deployment: deploymentName,
+			Reason:     ReasonProbeFailed,
+			Detail:     err.Error(),
+			ExitCode:   -1,
+		}
+		logNotReady(deployLog, failure)
+		return failure
+	}
+
+	deployLog.Log("✓ Health check passed")
+	return nil
+}
+
+// verifyHTTPHealth GETs the health path on every pod matching selector
+// through the API server's pod proxy until all of them pass, or timeout
+// passes.
+func (c *Client) verifyHTTPHealth(ctx context.Context, selector string, port int32, check types.HealthCheck, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		err := c.probePods(ctx, selector, port, check)
+		if err == nil {
+			return nil
+		}
+
+		c.logger.Debug("Health check not passing yet",
+			zap.String("selector", selector),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("health check timed out: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) probePods(ctx context.Context, selector string, port int32, check types.HealthCheck) error {
+	path := healthCheckPath(check)
+
+	pods, err := c.clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	probed := 0
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		var code int
+		result := c.clientset.CoreV1().RESTClient().Get().
+			Namespace(c.namespace).
+			Resource("pods").
+			SubResource("proxy").
+			Name(fmt.Sprintf("%s:%d", pod.Name, port)).
+			Suffix(path).
+			Do(ctx).
+			StatusCode(&code)
+
+		if code == 0 {
+			return fmt.Errorf("pod %s did not answer: %w", pod.Name, result.Error())
+		}
+		if !healthCheckPasses(check, code) {
+			return fmt.Errorf("pod %s: GET %s returned %d", pod.Name, path, code)
+		}
+		probed++
+	}
+
+	if probed == 0 {
+		return fmt.Errorf("no running pods match %s", selector)
+	}
+	return nil
+}
+
+// scheduleRetirement marks a Deployment for removal once the grace period
+// passes. It reports false if the Deployment doesn't exist.
+func (c *Client) scheduleRetirement(ctx context.Context, deploymentName string) (bool, error) {
+	retireAt := time.Now().Add(c.blueGreenGrace).UTC().Format(time.RFC3339)
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, retireAfterAnnotation, retireAt)
+
+	_, err := c.clientset.AppsV1().Deployments(c.namespace).Patch(
+		ctx, deploymentName, k8stypes.MergePatchType, []byte(patch), metav1.PatchOptions{},
+	)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to annotate deployment: %w", err)
+	}
+	return true, nil
+}
+
+// RetireDeployments deletes Deployments whose retirement grace period has
+// passed, unless a later deploy switched traffic back to them. It returns
+// how many were deleted.
+func (c *Client) RetireDeployments(ctx context.Context) (int, error) {
+	deployments, err := c.clientset.AppsV1().Deployments(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/managed-by=code2cloud",
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	retired := 0
+	for _, deployment := range deployments.Items {
+		retireAfter, ok := deployment.Annotations[retireAfterAnnotation]
+		if !ok {
+			continue
+		}
+
+		at, err := time.Parse(time.RFC3339, retireAfter)
+		if err != nil || time.Now().Before(at) {
+			continue
+		}
+
+		app := deployment.Labels["app"]
+		active, err := c.activeColor(ctx, app)
+		if err != nil {
+			return retired, err
+		}
+
+		// Only retire what the Service no longer routes to
+		color := deployment.Labels[colorLabel]
+		if color != "" && color == active {
+			continue
+		}
+		if color == "" && active == "" {
+			continue
+		}
+
+		if err := c.DeleteDeployment(ctx, deployment.Name); err != nil {
+			return retired, err
+		}
+
+		c.logger.Info("Retired previous deployment",
+			zap.String("deployment", deployment.Name),
+			zap.String("app", app),
+		)
+		retired++
+	}
+
+	return retired, nil
+}
+
+// deleteColors removes any blue/green Deployments of an app.
+func (c *Client) deleteColors(ctx context.Context, name string) error {
+	deployments, err := c.clientset.AppsV1().Deployments(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s,%s", name, colorLabel),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	for _, deployment := range deployments.Items {
+		if err := c.DeleteDeployment(ctx, deployment.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func colorDeploymentName(name, color string) string {
+	return buildNameWithSuffix(name, "-"+color)
+}
+
+func otherColor(color string) string {
+	if color == colorBlue {
+		return colorGreen
+	}
+	return colorBlue
+}
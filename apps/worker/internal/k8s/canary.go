@@ -0,0 +1,499 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"code2cloud/worker/internal/logging"
+)
+
+// ---------------------------------------------------------------------------
+// Canary deploys
+// ---------------------------------------------------------------------------
+// A canary deploy starts the new version in the idle blue/green color, then
+// has the Istio sidecars split the Service's traffic between the live and
+// new pods. A DestinationRule defines one subset per version (keyed on
+// code2cloud/deployment-id) and a VirtualService weights them. The weight
+// steps up on a schedule while the new pods are watched; if they stop being
+// ready or answer too many 5xx, traffic goes back to the live version and
+// the new one is removed. On promotion the Service selects the new color
+// directly and the Istio objects are deleted, leaving the same steady state
+// as a blue/green deploy.
+// ---------------------------------------------------------------------------
+
+var (
+	virtualServiceGVR = schema.GroupVersionResource{
+		Group:    "networking.istio.io",
+		Version:  "v1beta1",
+		Resource: "virtualservices",
+	}
+	destinationRuleGVR = schema.GroupVersionResource{
+		Group:    "networking.istio.io",
+		Version:  "v1beta1",
+		Resource: "destinationrules",
+	}
+)
+
+const (
+	subsetStable = "stable"
+	subsetCanary = "canary"
+
+	// Below this many requests in a step the error rate isn't judged
+	canaryMinRequests = 20
+
+	// Istio's pilot-agent serves merged app and Envoy metrics here
+	sidecarStatsPort = 15020
+	sidecarStatsPath = "/stats/prometheus"
+)
+
+func (c *Client) deployCanary(ctx context.Context, opts DeployOptions, deployLog *logging.StreamLogger) (*DeployResult, error) {
+	name := opts.ResourceName()
+
+	applyStart := time.Now()
+
+	active, err := c.activeColor(ctx, name)
+	if err != nil {
+		return nil, classifyAPIError(err)
+	}
+	next := otherColor(active)
+	nextName := colorDeploymentName(name, next)
+
+	// Before the first blue/green or canary deploy the live version is the
+	// rolling Deployment
+	previous := name
+	if active != "" {
+		previous = colorDeploymentName(name, active)
+	}
+
+	live, err := c.getDeployment(ctx, previous)
+	if err != nil {
+		return nil, classifyAPIError(err)
+	}
+
+	deployLog.Log(fmt.Sprintf("Deploying %s to Kubernetes (canary)...", name))
+
+	if err := c.applyIsolation(ctx, opts, deployLog); err != nil {
+		return nil, err
+	}
+
+	liveSelector, err := c.pinLivePods(ctx, opts, active, deployLog)
+	if err != nil {
+		return nil, classifyAPIError(err)
+	}
+
+	deployLog.Log(fmt.Sprintf("Creating %s deployment...", next))
+
+	if err := c.applyDeployment(ctx, opts, nextName, map[string]string{colorLabel: next}); err != nil {
+		return nil, classifyAPIError(fmt.Errorf("failed to create deployment: %w", err))
+	}
+
+	deployLog.Log(fmt.Sprintf("✓ Deployment %s created", nextName))
+
+	applyDuration := time.Since(applyStart)
+
+	deployLog.Log(fmt.Sprintf("Waiting for %s pods to be ready...", next))
+
+	readyStart := time.Now()
+
+	if err := c.WaitForDeploymentReady(ctx, nextName, 5*time.Minute); err != nil {
+		logNotReady(deployLog, err)
+		c.abandonColor(ctx, nextName, deployLog)
+		return nil, fmt.Errorf("%s never became ready, traffic stays on the previous version: %w", nextName, err)
+	}
+
+	deployLog.Log("✓ Pods are ready")
+
+	selector := fmt.Sprintf("app=%s,%s=%s", name, colorLabel, next)
+	if err := c.verifyRollout(ctx, opts, nextName, selector, deployLog); err != nil {
+		c.abandonColor(ctx, nextName, deployLog)
+		return nil, fmt.Errorf("%s failed its health check, traffic stays on the previous version: %w", nextName, err)
+	}
+
+	readyDuration := time.Since(readyStart)
+
+	// ── Shift traffic in steps ──
+	if live == nil {
+		deployLog.Log("No live version to compare against, sending all traffic to the new one")
+	} else {
+		stableID := live.Spec.Template.Labels["code2cloud/deployment-id"]
+
+		if err := c.runCanary(ctx, opts, stableID, nextName, deployLog); err != nil {
+			c.abortCanary(ctx, opts, liveSelector, nextName, deployLog)
+			return nil, fmt.Errorf("canary aborted, traffic is back on the previous version: %w", err)
+		}
+	}
+
+	// ── Promote ──
+	if err := c.applyService(ctx, opts, map[string]string{"app": name, colorLabel: next}); err != nil {
+		return nil, classifyAPIError(fmt.Errorf("failed to switch service: %w", err))
+	}
+
+	c.dropTrafficSplit(ctx, name)
+
+	deployLog.Log(fmt.Sprintf("✓ Service %s now routes to %s (port 80 → %d)", name, next, opts.Port))
+
+	hosts, err := c.applyIngress(ctx, opts, deployLog)
+	if err != nil {
+		return nil, err
+	}
+
+	if live != nil {
+		if _, err := c.scheduleRetirement(ctx, previous); err != nil {
+			c.logger.Warn("Failed to schedule retirement of previous version",
+				zap.String("deployment", previous),
+				zap.Error(err),
+			)
+		} else {
+			deployLog.Log(fmt.Sprintf("Previous version %s kept for %s", previous, c.blueGreenGrace))
+		}
+	}
+
+	result := newDeployResult(name, nextName, hosts, applyDuration, readyDuration)
+
+	c.logger.Info("Canary deployment complete",
+		zap.String("name", name),
+		zap.String("color", next),
+		zap.Strings("urls", result.URLs),
+	)
+
+	return result, nil
+}
+
+// runCanary steps the new version's traffic weight up, watching it for one
+// interval at each step.
+func (c *Client) runCanary(ctx context.Context, opts DeployOptions, stableID, canaryName string, deployLog *logging.StreamLogger) error {
+	name := opts.ResourceName()
+
+	// The split has to exist before the Service selects both versions,
+	// otherwise the new pods would get traffic by replica count
+	if err := c.applyTrafficSplit(ctx, opts, stableID, 0); err != nil {
+		return classifyAPIError(err)
+	}
+	if err := c.applyService(ctx, opts, map[string]string{"app": name}); err != nil {
+		return classifyAPIError(fmt.Errorf("failed to update service: %w", err))
+	}
+
+	selector := fmt.Sprintf("app=%s,code2cloud/deployment-id=%s", name, opts.DeploymentID)
+
+	for _, weight := range c.canary.Steps {
+		if weight <= 0 || weight >= 100 {
+			continue
+		}
+
+		if err := c.applyTrafficSplit(ctx, opts, stableID, weight); err != nil {
+			return classifyAPIError(err)
+		}
+
+		deployLog.Log(fmt.Sprintf("→ %d%% of traffic on the new version, watching for %s...", weight, c.canary.StepInterval))
+
+		if err := c.watchCanary(ctx, canaryName, selector); err != nil {
+			deployLog.Log(fmt.Sprintf("✗ %v", err))
+			return err
+		}
+
+		deployLog.Log(fmt.Sprintf("✓ Healthy at %d%%", weight))
+	}
+
+	return nil
+}
+
+// watchCanary polls the new version for one step interval. It fails as soon
+// as a pod stops being ready or the 5xx ratio seen by its sidecars since the
+// step began exceeds the limit.
+func (c *Client) watchCanary(ctx context.Context, canaryName, selector string) error {
+	baseTotal, baseErrors, err := c.sidecarRequestStats(ctx, selector)
+	if err != nil {
+		c.logger.Warn("Failed to read sidecar metrics, judging readiness only",
+			zap.String("selector", selector),
+			zap.Error(err),
+		)
+	}
+
+	deadline := time.After(c.canary.StepInterval)
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return nil
+		case <-ticker.C:
+		}
+
+		deployment, err := c.getDeployment(ctx, canaryName)
+		if err != nil {
+			return err
+		}
+		if deployment == nil {
+			return fmt.Errorf("deployment %s disappeared", canaryName)
+		}
+		if deployment.Status.ReadyReplicas < *deployment.Spec.Replicas {
+			return fmt.Errorf("readiness regressed: %d/%d pods ready",
+				deployment.Status.ReadyReplicas, *deployment.Spec.Replicas)
+		}
+
+		total, errors, err := c.sidecarRequestStats(ctx, selector)
+		if err != nil {
+			c.logger.Debug("Sidecar metrics unavailable", zap.Error(err))
+			continue
+		}
+
+		// Counters reset when a pod restarts
+		if total < baseTotal || errors < baseErrors {
+			baseTotal, baseErrors = 0, 0
+		}
+
+		requests := total - baseTotal
+		if requests < canaryMinRequests {
+			continue
+		}
+
+		rate := float64(errors-baseErrors) / float64(requests)
+		if rate > c.canary.MaxErrorRate {
+			return fmt.Errorf("error rate %.1f%% over %d requests exceeds %.1f%%",
+				rate*100, requests, c.canary.MaxErrorRate*100)
+		}
+	}
+}
+
+// abortCanary points the Service back at the live pods, as matched by
+// selector, and removes the new version.
+func (c *Client) abortCanary(ctx context.Context, opts DeployOptions, selector map[string]string, canaryName string, deployLog *logging.StreamLogger) {
+	name := opts.ResourceName()
+	ctx = context.WithoutCancel(ctx)
+
+	deployLog.Log("↩ Sending all traffic back to the previous version")
+
+	if selector == nil {
+		selector = map[string]string{"app": name}
+	}
+
+	// Point the Service back at the live pods before dropping the split so
+	// the new pods never get unweighted traffic
+	if err := c.applyService(ctx, opts, selector); err != nil {
+		c.logger.Error("Failed to restore service selector after canary abort",
+			zap.String("name", name),
+			zap.Error(err),
+		)
+	}
+
+	c.dropTrafficSplit(ctx, name)
+
+	c.abandonColor(ctx, canaryName, deployLog)
+}
+
+// applyTrafficSplit creates or updates the DestinationRule and
+// VirtualService sending weight percent of the app's traffic to this
+// deployment and the rest to stableID.
+func (c *Client) applyTrafficSplit(ctx context.Context, opts DeployOptions, stableID string, weight int) error {
+	name := opts.ResourceName()
+	host := fmt.Sprintf("%s.%s.svc.cluster.local", name, c.namespace)
+
+	labels := map[string]interface{}{
+		"app":                          name,
+		"app.kubernetes.io/managed-by": "code2cloud",
+		"code2cloud/project-id":        opts.ProjectID,
+	}
+	for k, v := range opts.environmentLabels() {
+		labels[k] = v
+	}
+
+	destinationRule := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "networking.istio.io/v1beta1",
+		"kind":       "DestinationRule",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": c.namespace,
+			"labels":    labels,
+		},
+		"spec": map[string]interface{}{
+			"host": host,
+			"subsets": []interface{}{
+				map[string]interface{}{
+					"name":   subsetStable,
+					"labels": map[string]interface{}{"code2cloud/deployment-id": stableID},
+				},
+				map[string]interface{}{
+					"name":   subsetCanary,
+					"labels": map[string]interface{}{"code2cloud/deployment-id": opts.DeploymentID},
+				},
+			},
+		},
+	}}
+
+	virtualService := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "networking.istio.io/v1beta1",
+		"kind":       "VirtualService",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": c.namespace,
+			"labels":    labels,
+		},
+		"spec": map[string]interface{}{
+			"hosts": []interface{}{host},
+			"http": []interface{}{
+				map[string]interface{}{
+					"route": []interface{}{
+						map[string]interface{}{
+							"destination": map[string]interface{}{"host": host, "subset": subsetStable},
+							"weight":      int64(100 - weight),
+						},
+						map[string]interface{}{
+							"destination": map[string]interface{}{"host": host, "subset": subsetCanary},
+							"weight":      int64(weight),
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	if err := c.applyUnstructured(ctx, destinationRuleGVR, destinationRule); err != nil {
+		return fmt.Errorf("failed to apply destination rule: %w", err)
+	}
+	if err := c.applyUnstructured(ctx, virtualServiceGVR, virtualService); err != nil {
+		return fmt.Errorf("failed to apply virtual service: %w", err)
+	}
+
+	c.logger.Info("Traffic split updated",
+		zap.String("name", name),
+		zap.Int("canary_weight", weight),
+	)
+	return nil
+}
+
+func (c *Client) applyUnstructured(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	resources := c.dynamicClient.Resource(gvr).Namespace(c.namespace)
+
+	existing, err := resources.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			_, err = resources.Create(ctx, obj, metav1.CreateOptions{})
+			return err
+		}
+		return err
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	_, err = resources.Update(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+// deleteTrafficSplit removes the app's VirtualService and DestinationRule.
+// Missing objects, or Istio not being installed, aren't errors.
+func (c *Client) deleteTrafficSplit(ctx context.Context, name string) error {
+	for _, gvr := range []schema.GroupVersionResource{virtualServiceGVR, destinationRuleGVR} {
+		err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s: %w", gvr.Resource, err)
+		}
+	}
+	return nil
+}
+
+// dropTrafficSplit removes a split left behind by an interrupted canary
+// deploy, which would otherwise keep overriding the Service's routing.
+func (c *Client) dropTrafficSplit(ctx context.Context, name string) {
+	if err := c.deleteTrafficSplit(ctx, name); err != nil {
+		c.logger.Warn("Failed to remove canary traffic split",
+			zap.String("name", name),
+			zap.Error(err),
+		)
+	}
+}
+
+// sidecarRequestStats sums the requests, and the 5xx among them, that the
+// sidecars of the matching pods have served.
+func (c *Client) sidecarRequestStats(ctx context.Context, selector string) (total, errors int64, err error) {
+	pods, err := c.clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		body, err := c.clientset.CoreV1().RESTClient().Get().
+			Namespace(c.namespace).
+			Resource("pods").
+			SubResource("proxy").
+			Name(fmt.Sprintf("%s:%d", pod.Name, sidecarStatsPort)).
+			Suffix(sidecarStatsPath).
+			DoRaw(ctx)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read metrics of pod %s: %w", pod.Name, err)
+		}
+
+		podTotal, podErrors := parseRequestCounts(string(body))
+		total += podTotal
+		errors += podErrors
+	}
+
+	return total, errors, nil
+}
+
+// parseRequestCounts reads istio_requests_total samples reported by the
+// receiving side from Prometheus text output.
+func parseRequestCounts(metrics string) (total, errors int64) {
+	scanner := bufio.NewScanner(strings.NewReader(metrics))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "istio_requests_total{") ||
+			!strings.Contains(line, `reporter="destination"`) {
+			continue
+		}
+
+		end := strings.LastIndex(line, "}")
+		if end < 0 {
+			continue
+		}
+		fields := strings.Fields(line[end+1:])
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+
+		total += int64(value)
+		if strings.Contains(line[:end], `response_code="5`) {
+			errors += int64(value)
+		}
+	}
+
+	return total, errors
+}
+
+// getDeployment returns nil if the Deployment doesn't exist.
+func (c *Client) getDeployment(ctx context.Context, name string) (*appsv1.Deployment, error) {
+	deployment, err := c.clientset.AppsV1().Deployments(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	return deployment, nil
+}
@@ -2,9 +2,11 @@ package k8s
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
+	"code2cloud/worker/internal/metrics"
 	"code2cloud/worker/internal/types"
 
 	"go.uber.org/zap"
@@ -64,6 +66,8 @@ func (cw *CleanupWorker) Start(ctx context.Context) {
 		)
 
 		cw.cleanupExpired(ctx)
+		cw.retireDeployments(ctx)
+		cw.pruneEnvSecrets(ctx)
 
 		ticker := time.NewTicker(cw.checkInterval)
 		defer ticker.Stop()
@@ -75,6 +79,8 @@ func (cw *CleanupWorker) Start(ctx context.Context) {
 				return
 			case <-ticker.C:
 				cw.cleanupExpired(ctx)
+				cw.retireDeployments(ctx)
+				cw.pruneEnvSecrets(ctx)
 			}
 		}
 	}()
@@ -91,10 +97,13 @@ func (cw *CleanupWorker) cleanupExpired(ctx context.Context) {
 
 	deployments, err := cw.fetchExpiredDeployments(ctx)
 	if err != nil {
+		metrics.CleanupRuns.WithLabelValues("expired", "error").Inc()
 		cw.logger.Warn("Failed to fetch expired deployments", zap.Error(err))
 		return
 	}
 
+	metrics.CleanupRuns.WithLabelValues("expired", "success").Inc()
+
 	if len(deployments) == 0 {
 		return
 	}
@@ -108,14 +117,101 @@ func (cw *CleanupWorker) cleanupExpired(ctx context.Context) {
 			return
 		}
 
-		cw.cleanupDeployment(ctx, deployment)
+		if deployment.ScaleToZero {
+			cw.sleepDeployment(ctx, deployment)
+		} else {
+			cw.cleanupDeployment(ctx, deployment)
+		}
+	}
+}
+
+// retireDeployments removes blue/green colors whose grace period has passed.
+func (cw *CleanupWorker) retireDeployments(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	retired, err := cw.client.RetireDeployments(ctx)
+	if err != nil {
+		metrics.CleanupRuns.WithLabelValues("retire", "error").Inc()
+		cw.logger.Warn("Failed to retire previous deployments", zap.Error(err))
+		return
+	}
+
+	metrics.CleanupRuns.WithLabelValues("retire", "success").Inc()
+
+	if retired > 0 {
+		cw.logger.Info("Retired previous deployments", zap.Int("count", retired))
+	}
+}
+
+// pruneEnvSecrets removes env Secrets of versions that can no longer run.
+func (cw *CleanupWorker) pruneEnvSecrets(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	pruned, err := cw.client.PruneEnvSecrets(ctx)
+	if err != nil {
+		metrics.CleanupRuns.WithLabelValues("env_secrets", "error").Inc()
+		cw.logger.Warn("Failed to prune env secrets", zap.Error(err))
+		return
+	}
+
+	metrics.CleanupRuns.WithLabelValues("env_secrets", "success").Inc()
+
+	if pruned > 0 {
+		cw.logger.Info("Pruned env secrets", zap.Int("count", pruned))
+	}
+}
+
+// sleepDeployment scales an idle deployment to zero, leaving its Service
+// and Ingress for the activator to wake it through.
+func (cw *CleanupWorker) sleepDeployment(ctx context.Context, deployment types.ExpiredDeployment) {
+	cw.logger.Info("Scaling idle deployment to zero",
+		zap.String("deployment", deployment.ID),
+		zap.String("project", deployment.ProjectName),
+		zap.String("environment", deployment.Environment),
+		zap.Int("ttl_minutes", deployment.TTLMinutes),
+	)
+
+	name := ResourceName(deployment.ProjectName, deployment.Environment, deployment.Branch)
+	if err := cw.client.ScaleToZero(ctx, name); err != nil {
+		cw.logger.Warn("Failed to scale deployment to zero (will retry next cycle)",
+			zap.String("deployment", deployment.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if cw.logStreamer != nil {
+		cw.logStreamer.StopStreaming(deployment.ID)
+	}
+
+	systemLog := cw.client.logFactory.CreateSystemLogger(deployment.ID)
+	systemLog.Log(fmt.Sprintf("💤 Scaled to zero after %d minutes idle; the next request wakes it up",
+		deployment.TTLMinutes))
+	systemLog.Close()
+
+	if err := cw.updateDeploymentStatus(ctx, deployment.ID, types.StatusSleeping); err != nil {
+		cw.logger.Error("Failed to mark deployment as sleeping",
+			zap.String("deployment", deployment.ID),
+			zap.Error(err),
+		)
+		return
 	}
+
+	cw.logger.Info("Deployment scaled to zero 💤",
+		zap.String("deployment", deployment.ID),
+		zap.String("project", deployment.ProjectName),
+	)
 }
 
 func (cw *CleanupWorker) cleanupDeployment(ctx context.Context, deployment types.ExpiredDeployment) {
 	cw.logger.Info("Cleaning up expired deployment",
 		zap.String("deployment", deployment.ID),
 		zap.String("project", deployment.ProjectName),
+		zap.String("environment", deployment.Environment),
 		zap.Int("ttl_minutes", deployment.TTLMinutes),
 	)
 
@@ -126,6 +222,8 @@ func (cw *CleanupWorker) cleanupDeployment(ctx context.Context, deployment types
 	if err := cw.client.Cleanup(ctx, CleanupOptions{
 		DeploymentID: deployment.ID,
 		ProjectName:  deployment.ProjectName,
+		Environment:  deployment.Environment,
+		Branch:       deployment.Branch,
 	}); err != nil {
 		cw.logger.Warn("Partial cleanup failure (will retry next cycle)",
 			zap.String("deployment", deployment.ID),
@@ -141,11 +239,14 @@ func (cw *CleanupWorker) cleanupDeployment(ctx context.Context, deployment types
 		return
 	}
 
-	if err := cw.updateProjectStatus(ctx, deployment.ProjectID, "INACTIVE"); err != nil {
-		cw.logger.Warn("Failed to update project status",
-			zap.String("project", deployment.ProjectID),
-			zap.Error(err),
-		)
+	// An expired preview leaves production's status alone
+	if deployment.Environment != types.EnvironmentPreview {
+		if err := cw.updateProjectStatus(ctx, deployment.ProjectID, "INACTIVE"); err != nil {
+			cw.logger.Warn("Failed to update project status",
+				zap.String("project", deployment.ProjectID),
+				zap.Error(err),
+			)
+		}
 	}
 
 	cw.logger.Info("Deployment cleaned up successfully 🧹",
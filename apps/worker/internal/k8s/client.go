@@ -1,11 +1,14 @@
 package k8s
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -21,11 +24,35 @@ type Client struct {
 	baseDomain    string
 	logFactory    *logging.Factory
 	logger        *zap.Logger
+
+	// How long a blue/green deploy keeps the previous color running
+	blueGreenGrace time.Duration
+
+	canary CanaryConfig
 }
 
 type Config struct {
 	Namespace  string
 	BaseDomain string
+
+	// Defaults to 10 minutes
+	BlueGreenGracePeriod time.Duration
+
+	Canary CanaryConfig
+}
+
+// CanaryConfig controls how a canary deploy shifts traffic.
+type CanaryConfig struct {
+	// Percentages of traffic sent to the new version before it takes all
+	// of it. Defaults to 10, 25, 50.
+	Steps []int
+
+	// How long each step is watched. Defaults to 1 minute.
+	StepInterval time.Duration
+
+	// Share of 5xx responses from the new version that aborts the rollout.
+	// Defaults to 0.05.
+	MaxErrorRate float64
 }
 
 func NewClient(config Config, logFactory *logging.Factory, logger *zap.Logger) (*Client, error) {
@@ -49,13 +76,31 @@ func NewClient(config Config, logFactory *logging.Factory, logger *zap.Logger) (
 		zap.String("baseDomain", config.BaseDomain),
 	)
 
+	grace := config.BlueGreenGracePeriod
+	if grace == 0 {
+		grace = 10 * time.Minute
+	}
+
+	canary := config.Canary
+	if len(canary.Steps) == 0 {
+		canary.Steps = []int{10, 25, 50}
+	}
+	if canary.StepInterval == 0 {
+		canary.StepInterval = time.Minute
+	}
+	if canary.MaxErrorRate == 0 {
+		canary.MaxErrorRate = 0.05
+	}
+
 	return &Client{
-		clientset:     clientset,
-		dynamicClient: dynamicClient,
-		namespace:     config.Namespace,
-		baseDomain:    config.BaseDomain,
-		logFactory:    logFactory,
-		logger:        logger,
+		clientset:      clientset,
+		dynamicClient:  dynamicClient,
+		namespace:      config.Namespace,
+		baseDomain:     config.BaseDomain,
+		logFactory:     logFactory,
+		logger:         logger,
+		blueGreenGrace: grace,
+		canary:         canary,
 	}, nil
 }
 
@@ -94,6 +139,15 @@ func (c *Client) Namespace() string {
 	return c.namespace
 }
 
+// Ping verifies the API server is reachable and the worker can still read
+// its target namespace.
+func (c *Client) Ping(ctx context.Context) error {
+	if _, err := c.clientset.CoreV1().Services(c.namespace).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		return fmt.Errorf("kubernetes api not reachable: %w", err)
+	}
+	return nil
+}
+
 func int32Ptr(i int32) *int32    { return &i }
 func int64Ptr(i int64) *int64    { return &i }
 func boolPtr(b bool) *bool       { return &b }
@@ -2,17 +2,21 @@ package k8s
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
 
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"code2cloud/worker/internal/logging"
+	"code2cloud/worker/internal/types"
 )
 
 func (c *Client) Deploy(ctx context.Context, opts DeployOptions) (*DeployResult, error) {
-	name := sanitizeK8sName(opts.ProjectName)
+	name := opts.ResourceName()
 
 	deployLog := c.logFactory.CreatePrefixedLogger(
 		opts.DeploymentID,
@@ -23,89 +27,209 @@ func (c *Client) Deploy(ctx context.Context, opts DeployOptions) (*DeployResult,
 
 	c.logger.Info("Starting Kubernetes deployment",
 		zap.String("name", name),
+		zap.String("environment", opts.environment()),
+		zap.String("strategy", opts.strategy()),
 		zap.String("image", opts.ImageName),
 		zap.Strings("domains", opts.Domains),
 	)
 
-	deployLog.Log(fmt.Sprintf("Deploying %s to Kubernetes...", name))
-	deployLog.Log("Creating service account...")
-
-	if err := c.CreateOrUpdateServiceAccount(ctx, opts); err != nil {
-		return nil, fmt.Errorf("failed to create service account: %w", err)
+	switch opts.strategy() {
+	case types.StrategyBlueGreen:
+		return c.deployBlueGreen(ctx, opts, deployLog)
+	case types.StrategyCanary:
+		return c.deployCanary(ctx, opts, deployLog)
 	}
 
-	deployLog.Log("✓ Service account created")
-	deployLog.Log("Creating network policy...")
+	applyStart := time.Now()
 
-	if err := c.CreateOrUpdateNetworkPolicy(ctx, opts); err != nil {
-		return nil, fmt.Errorf("failed to create network policy: %w", err)
+	deployLog.Log(fmt.Sprintf("Deploying %s to Kubernetes...", name))
+
+	if err := c.applyIsolation(ctx, opts, deployLog); err != nil {
+		return nil, err
 	}
 
-	deployLog.Log("✓ Network policy created (tenant isolation)")
 	deployLog.Log("Creating deployment...")
 
 	if err := c.CreateOrUpdateDeployment(ctx, opts); err != nil {
-		return nil, fmt.Errorf("failed to create deployment: %w", err)
+		return nil, classifyAPIError(fmt.Errorf("failed to create deployment: %w", err))
 	}
 
 	deployLog.Log(fmt.Sprintf("✓ Deployment %s created", name))
 	deployLog.Log("Creating service...")
 
 	if err := c.CreateOrUpdateService(ctx, opts); err != nil {
-		return nil, fmt.Errorf("failed to create service: %w", err)
+		return nil, classifyAPIError(fmt.Errorf("failed to create service: %w", err))
 	}
 
 	deployLog.Log(fmt.Sprintf("✓ Service %s created (port 80 → %d)", name, opts.Port))
-	deployLog.Log("Creating ingress...")
 
-	hosts, err := c.CreateOrUpdateIngress(ctx, opts)
+	c.dropTrafficSplit(ctx, name)
+
+	hosts, err := c.applyIngress(ctx, opts, deployLog)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create ingress: %w", err)
+		return nil, err
 	}
 
-	for _, host := range hosts {
-		deployLog.Log(fmt.Sprintf("✓ Ingress configured: https://%s", host))
-	}
+	applyDuration := time.Since(applyStart)
 
 	deployLog.Log("Waiting for pods to be ready...")
 
+	readyStart := time.Now()
+
 	if err := c.WaitForDeploymentReady(ctx, name, 5*time.Minute); err != nil {
-		c.logger.Warn("Deployment not ready within timeout",
+		c.logger.Warn("Deployment not ready",
 			zap.String("name", name),
 			zap.Error(err),
 		)
-		deployLog.Log("⚠ Warning: Deployment may still be starting up")
-		// Don't fail - the deployment might just be slow
-	} else {
-		deployLog.Log("✓ Pods are ready and healthy")
+		logNotReady(deployLog, err)
+		c.revertRollout(ctx, name, deployLog)
+		return nil, err
+	}
+
+	deployLog.Log("✓ Pods are ready and healthy")
+
+	// The kubelet only tells 2xx/3xx from the rest, so an exact expected
+	// status is checked here before the rollout counts as done
+	if opts.HealthCheck.ExpectedStatus != 0 {
+		selector := fmt.Sprintf("app=%s,code2cloud/deployment-id=%s", name, opts.DeploymentID)
+		if err := c.verifyRollout(ctx, opts, name, selector, deployLog); err != nil {
+			c.revertRollout(ctx, name, deployLog)
+			return nil, err
+		}
+	}
+
+	readyDuration := time.Since(readyStart)
+
+	// The Service now selects every pod of the app, so drop blue/green
+	// colors left over from an earlier strategy
+	if err := c.deleteColors(ctx, name); err != nil {
+		c.logger.Warn("Failed to remove blue/green deployments",
+			zap.String("name", name),
+			zap.Error(err),
+		)
+	}
+
+	result := newDeployResult(name, name, hosts, applyDuration, readyDuration)
+
+	c.logger.Info("Kubernetes deployment complete",
+		zap.String("name", name),
+		zap.Strings("urls", result.URLs),
+	)
+
+	return result, nil
+}
+
+// revertRollout returns a Deployment whose new pods never became ready to
+// its previous ReplicaSet, so the last working version keeps serving.
+func (c *Client) revertRollout(ctx context.Context, name string, deployLog *logging.StreamLogger) {
+	ctx = context.WithoutCancel(ctx)
+
+	rolledBack, err := c.RollbackDeployment(ctx, name)
+	switch {
+	case err != nil:
+		c.logger.Error("Failed to roll back deployment",
+			zap.String("name", name),
+			zap.Error(err),
+		)
+		deployLog.Log(fmt.Sprintf("✗ Rollback failed: %v", err))
+	case rolledBack:
+		deployLog.Log("↩ Rolled back to the previous version")
+	default:
+		deployLog.Log("No previous version to roll back to")
+	}
+}
+
+// applyIsolation creates the per-app service account and network policy
+// shared by every deploy strategy.
+func (c *Client) applyIsolation(ctx context.Context, opts DeployOptions, deployLog *logging.StreamLogger) error {
+	deployLog.Log("Creating service account...")
+
+	if err := c.CreateOrUpdateServiceAccount(ctx, opts); err != nil {
+		return classifyAPIError(fmt.Errorf("failed to create service account: %w", err))
+	}
+
+	deployLog.Log("✓ Service account created")
+	deployLog.Log("Creating network policy...")
+
+	if err := c.CreateOrUpdateNetworkPolicy(ctx, opts); err != nil {
+		return classifyAPIError(fmt.Errorf("failed to create network policy: %w", err))
+	}
+
+	deployLog.Log("✓ Network policy created (tenant isolation)")
+	return nil
+}
+
+func (c *Client) applyIngress(ctx context.Context, opts DeployOptions, deployLog *logging.StreamLogger) ([]string, error) {
+	deployLog.Log("Creating ingress...")
+
+	hosts, err := c.CreateOrUpdateIngress(ctx, opts)
+	if err != nil {
+		return nil, classifyAPIError(fmt.Errorf("failed to create ingress: %w", err))
 	}
 
-	// Build result
+	for _, host := range hosts {
+		deployLog.Log(fmt.Sprintf("✓ Ingress configured: https://%s", host))
+	}
+	return hosts, nil
+}
+
+func newDeployResult(name, deploymentName string, hosts []string, applyDuration, readyDuration time.Duration) *DeployResult {
 	urls := make([]string, 0, len(hosts))
 	for _, host := range hosts {
 		urls = append(urls, fmt.Sprintf("https://%s", host))
 	}
 
-	result := &DeployResult{
-		DeploymentName: name,
+	return &DeployResult{
+		AppName:        name,
+		DeploymentName: deploymentName,
 		ServiceName:    name,
 		IngressName:    name,
 		URLs:           urls,
 		Ready:          true,
+		ApplyDuration:  applyDuration,
+		ReadyDuration:  readyDuration,
 	}
+}
 
-	c.logger.Info("Kubernetes deployment complete",
-		zap.String("name", name),
-		zap.Strings("urls", urls),
-	)
-
-	return result, nil
+func (c *Client) Cleanup(ctx context.Context, opts CleanupOptions) error {
+	return c.cleanupResources(ctx, ResourceName(opts.ProjectName, opts.Environment, opts.Branch))
 }
 
+// CleanupPreviews removes every preview environment of a project. Previews
+// are found by label since their branches aren't known to the caller.
+func (c *Client) CleanupPreviews(ctx context.Context, projectID string) error {
+	selector := fmt.Sprintf("code2cloud/project-id=%s,code2cloud/environment=%s",
+		projectID, environmentLabel(types.EnvironmentPreview))
 
-func (c *Client) Cleanup(ctx context.Context, opts CleanupOptions) error {
-	name := sanitizeK8sName(opts.ProjectName)
+	deployments, err := c.clientset.AppsV1().Deployments(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list preview deployments: %w", err)
+	}
 
+	// Blue/green previews have two Deployments per app
+	apps := make(map[string]bool)
+	for _, deployment := range deployments.Items {
+		if app := deployment.Labels["app"]; app != "" {
+			apps[app] = true
+		}
+	}
+
+	var errs []string
+	for app := range apps {
+		if err := c.cleanupResources(ctx, app); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", app, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("preview cleanup errors: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (c *Client) cleanupResources(ctx context.Context, name string) error {
 	c.logger.Info("Cleaning up Kubernetes resources",
 		zap.String("name", name),
 	)
@@ -125,6 +249,22 @@ func (c *Client) Cleanup(ctx context.Context, opts CleanupOptions) error {
 		errs = append(errs, fmt.Sprintf("deployment: %v", err))
 	}
 
+	if err := c.deleteColors(ctx, name); err != nil {
+		errs = append(errs, fmt.Sprintf("blue/green deployments: %v", err))
+	}
+
+	if err := c.deleteTrafficSplit(ctx, name); err != nil {
+		errs = append(errs, fmt.Sprintf("traffic split: %v", err))
+	}
+
+	if err := c.DeleteDisruptionBudget(ctx, name); err != nil {
+		errs = append(errs, fmt.Sprintf("disruption budget: %v", err))
+	}
+
+	if err := c.deleteEnvSecrets(ctx, name); err != nil {
+		errs = append(errs, fmt.Sprintf("env secrets: %v", err))
+	}
+
 	if err := c.DeleteNetworkPolicy(ctx, name); err != nil {
 		errs = append(errs, fmt.Sprintf("network policy: %v", err))
 	}
@@ -141,6 +281,60 @@ func (c *Client) Cleanup(ctx context.Context, opts CleanupOptions) error {
 	return nil
 }
 
+// ResourceName returns the Kubernetes resource name used for a project in
+// an environment. Production keeps the bare project name; each preview
+// branch gets its own name so it never touches production's resources.
+func ResourceName(projectName, environment, branch string) string {
+	if environment != types.EnvironmentPreview {
+		return sanitizeK8sName(projectName)
+	}
+
+	// The branch hash keeps names unique when sanitizing or truncating
+	// would otherwise map two branches ("feat/a", "feat-a") to one name.
+	sum := sha1.Sum([]byte(branch))
+	suffix := "-" + hex.EncodeToString(sum[:])[:6]
+
+	return buildNameWithSuffix(sanitizeK8sName(projectName+"-"+branch), suffix)
+}
+
+// ResourceName returns the name shared by all of the deployment's resources.
+func (o DeployOptions) ResourceName() string {
+	return ResourceName(o.ProjectName, o.Environment, o.Branch)
+}
+
+func (o DeployOptions) environment() string {
+	if o.Environment == "" {
+		return types.EnvironmentProduction
+	}
+	return o.Environment
+}
+
+func (o DeployOptions) isPreview() bool {
+	return o.Environment == types.EnvironmentPreview
+}
+
+func (o DeployOptions) strategy() string {
+	if o.Strategy == "" {
+		return types.StrategyRolling
+	}
+	return o.Strategy
+}
+
+// environmentLabels tags resources with their environment, and previews
+// with their branch, so previews can be found without knowing their names.
+func (o DeployOptions) environmentLabels() map[string]string {
+	labels := map[string]string{
+		"code2cloud/environment": environmentLabel(o.environment()),
+	}
+	if o.isPreview() {
+		labels["code2cloud/branch"] = sanitizeK8sName(o.Branch)
+	}
+	return labels
+}
+
+func environmentLabel(environment string) string {
+	return strings.ToLower(environment)
+}
 
 func sanitizeK8sName(name string) string {
 	name = strings.ToLower(name)
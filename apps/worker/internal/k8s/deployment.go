@@ -3,6 +3,7 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -16,11 +17,18 @@ import (
 
 
 func (c *Client) CreateOrUpdateDeployment(ctx context.Context, opts DeployOptions) error {
-	name := sanitizeK8sName(opts.ProjectName)
+	return c.applyDeployment(ctx, opts, opts.ResourceName(), nil)
+}
+
+// applyDeployment creates or updates the Deployment deploymentName running
+// opts.ImageName. selectorLabels are added to the pod labels and selector on
+// top of "app", so blue/green colors can run side by side under one app.
+func (c *Client) applyDeployment(ctx context.Context, opts DeployOptions, deploymentName string, selectorLabels map[string]string) error {
+	name := opts.ResourceName()
 	serviceAccountName := buildServiceAccountName(name)
 
 	c.logger.Info("Creating/updating deployment",
-		zap.String("name", name),
+		zap.String("name", deploymentName),
 		zap.String("image", opts.ImageName),
 		zap.Int32("replicas", opts.Replicas),
 	)
@@ -33,14 +41,27 @@ func (c *Client) CreateOrUpdateDeployment(ctx context.Context, opts DeployOption
 		"code2cloud/project-id":        opts.ProjectID,
 	}
 
+	for k, v := range opts.environmentLabels() {
+		labels[k] = v
+	}
 	for k, v := range opts.Labels {
 		labels[k] = v
 	}
 
-	envVars := make([]corev1.EnvVar, 0, len(opts.EnvVars)+2)
-	for key, value := range opts.EnvVars {
-		envVars = append(envVars, corev1.EnvVar{Name: key, Value: value})
+	selector := map[string]string{"app": name}
+	for k, v := range selectorLabels {
+		selector[k] = v
+		labels[k] = v
+	}
+
+	// User values are loaded from the deploy's Secret; only defaults the
+	// user didn't set are written inline
+	envSecret, err := c.applyEnvSecret(ctx, opts, deploymentName)
+	if err != nil {
+		return err
 	}
+
+	envVars := make([]corev1.EnvVar, 0, 2)
 	if _, hasPort := opts.EnvVars["PORT"]; !hasPort {
 		envVars = append(envVars, corev1.EnvVar{Name: "PORT", Value: fmt.Sprintf("%d", opts.Port)})
 	}
@@ -48,16 +69,18 @@ func (c *Client) CreateOrUpdateDeployment(ctx context.Context, opts DeployOption
 		envVars = append(envVars, corev1.EnvVar{Name: "NODE_ENV", Value: "production"})
 	}
 
+	probes := buildProbes(opts)
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
+			Name:      deploymentName,
 			Namespace: c.namespace,
 			Labels:    labels,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: int32Ptr(opts.Replicas),
 			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"app": name},
+				MatchLabels: selector,
 			},
 			Strategy: appsv1.DeploymentStrategy{
 				Type: appsv1.RollingUpdateDeploymentStrategyType,
@@ -77,11 +100,12 @@ func (c *Client) CreateOrUpdateDeployment(ctx context.Context, opts DeployOption
 				Spec: corev1.PodSpec{
 					TerminationGracePeriodSeconds: int64Ptr(30),
 					ServiceAccountName:            serviceAccountName,
+					TopologySpreadConstraints:     spreadConstraints(opts, selector),
 
 					Containers: []corev1.Container{{
 						Name:            name,
 						Image:           opts.ImageName,
-						ImagePullPolicy: corev1.PullAlways,
+						ImagePullPolicy: imagePullPolicy(opts.ImageName),
 
 						Ports: []corev1.ContainerPort{{
 							Name:          "http",
@@ -89,7 +113,12 @@ func (c *Client) CreateOrUpdateDeployment(ctx context.Context, opts DeployOption
 							Protocol:      corev1.ProtocolTCP,
 						}},
 
-						Env: envVars,
+						Env:     envVars,
+						EnvFrom: envFromSecret(envSecret),
+
+						// Crashes report the log tail when the app leaves no
+						// termination message, so the build log can show why
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 
 						Resources: corev1.ResourceRequirements{
 							Requests: corev1.ResourceList{
@@ -102,29 +131,9 @@ func (c *Client) CreateOrUpdateDeployment(ctx context.Context, opts DeployOption
 							},
 						},
 
-						LivenessProbe: &corev1.Probe{
-							ProbeHandler: corev1.ProbeHandler{
-								TCPSocket: &corev1.TCPSocketAction{
-									Port: intstr.FromInt32(opts.Port),
-								},
-							},
-							InitialDelaySeconds: 10,
-							PeriodSeconds:       30,
-							TimeoutSeconds:      5,
-							FailureThreshold:    3,
-						},
-
-						ReadinessProbe: &corev1.Probe{
-							ProbeHandler: corev1.ProbeHandler{
-								TCPSocket: &corev1.TCPSocketAction{
-									Port: intstr.FromInt32(opts.Port),
-								},
-							},
-							InitialDelaySeconds: 5,
-							PeriodSeconds:       10,
-							TimeoutSeconds:      3,
-							FailureThreshold:    3,
-						},
+						LivenessProbe:  probes.liveness,
+						ReadinessProbe: probes.readiness,
+						StartupProbe:   probes.startup,
 					}},
 				},
 			},
@@ -133,7 +142,7 @@ func (c *Client) CreateOrUpdateDeployment(ctx context.Context, opts DeployOption
 
 	deploymentsClient := c.clientset.AppsV1().Deployments(c.namespace)
 
-	existing, err := deploymentsClient.Get(ctx, name, metav1.GetOptions{})
+	existing, err := deploymentsClient.Get(ctx, deploymentName, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Create new deployment
@@ -141,20 +150,31 @@ func (c *Client) CreateOrUpdateDeployment(ctx context.Context, opts DeployOption
 			if err != nil {
 				return fmt.Errorf("failed to create deployment: %w", err)
 			}
-			c.logger.Info("Deployment created", zap.String("name", name))
-			return nil
+			c.logger.Info("Deployment created", zap.String("name", deploymentName))
+			return c.applyScalingPolicies(ctx, opts, deploymentName)
 		}
 		return fmt.Errorf("failed to get deployment: %w", err)
 	}
 
 	deployment.ResourceVersion = existing.ResourceVersion
+	deployment.Spec.Replicas = int32Ptr(opts.initialReplicas(existing.Spec.Replicas))
+
 	_, err = deploymentsClient.Update(ctx, deployment, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to update deployment: %w", err)
 	}
 
-	c.logger.Info("Deployment updated", zap.String("name", name))
-	return nil
+	c.logger.Info("Deployment updated", zap.String("name", deploymentName))
+	return c.applyScalingPolicies(ctx, opts, deploymentName)
+}
+
+// applyScalingPolicies keeps the autoscaler and disruption budget in line
+// with the app's replica settings.
+func (c *Client) applyScalingPolicies(ctx context.Context, opts DeployOptions, deploymentName string) error {
+	if err := c.applyAutoscaler(ctx, opts, deploymentName); err != nil {
+		return err
+	}
+	return c.applyDisruptionBudget(ctx, opts)
 }
 
 
@@ -172,11 +192,18 @@ func (c *Client) DeleteDeployment(ctx context.Context, name string) error {
 		return fmt.Errorf("failed to delete deployment: %w", err)
 	}
 
+	if err := c.DeleteAutoscaler(ctx, name); err != nil {
+		return err
+	}
+
 	c.logger.Info("Deployment deleted", zap.String("name", name))
 	return nil
 }
 
-// WaitForDeploymentReady waits for a deployment to be ready
+// WaitForDeploymentReady waits for a deployment's rollout to finish. It
+// fails early with a *ReadinessError when the new pods are crash looping,
+// can't pull their image or were OOM killed, and on timeout reports why
+// they never became ready.
 func (c *Client) WaitForDeploymentReady(ctx context.Context, name string, timeout time.Duration) error {
 	name = sanitizeK8sName(name)
 
@@ -185,25 +212,30 @@ func (c *Client) WaitForDeploymentReady(ctx context.Context, name string, timeou
 		zap.Duration("timeout", timeout),
 	)
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
+	var deployment *appsv1.Deployment
+
 	for {
 		select {
-		case <-ctx.Done():
-			return fmt.Errorf("timeout waiting for deployment %s to be ready", name)
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return c.diagnoseNotReady(ctx, name, deployment)
 		case <-ticker.C:
-			deployment, err := c.clientset.AppsV1().Deployments(c.namespace).Get(ctx, name, metav1.GetOptions{})
+			current, err := c.clientset.AppsV1().Deployments(c.namespace).Get(waitCtx, name, metav1.GetOptions{})
 			if err != nil {
 				c.logger.Warn("Failed to get deployment status", zap.Error(err))
 				continue
 			}
+			deployment = current
 
-			// Check if deployment is ready
-			if deployment.Status.ReadyReplicas >= *deployment.Spec.Replicas {
+			if rolloutComplete(deployment) {
 				c.logger.Info("Deployment is ready",
 					zap.String("name", name),
 					zap.Int32("ready", deployment.Status.ReadyReplicas),
@@ -211,11 +243,38 @@ func (c *Client) WaitForDeploymentReady(ctx context.Context, name string, timeou
 				return nil
 			}
 
+			if failure := c.podFailure(waitCtx, deployment); failure != nil {
+				return failure
+			}
+
 			c.logger.Debug("Deployment not ready yet",
 				zap.String("name", name),
 				zap.Int32("ready", deployment.Status.ReadyReplicas),
+				zap.Int32("updated", deployment.Status.UpdatedReplicas),
 				zap.Int32("desired", *deployment.Spec.Replicas),
 			)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// rolloutComplete reports whether every replica runs the current template
+// and is ready. Counting ready replicas alone would accept the old pods
+// that a rolling update keeps serving until the new ones are up.
+func rolloutComplete(deployment *appsv1.Deployment) bool {
+	desired := *deployment.Spec.Replicas
+	status := deployment.Status
+
+	return status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas >= desired &&
+		status.Replicas == status.UpdatedReplicas &&
+		status.ReadyReplicas >= desired
+}
+
+// imagePullPolicy pulls a tag on every start, since it may have been
+// rebuilt; an image pinned to a digest can't change and is pulled once.
+func imagePullPolicy(image string) corev1.PullPolicy {
+	if strings.Contains(image, "@sha256:") {
+		return corev1.PullIfNotPresent
+	}
+	return corev1.PullAlways
+}
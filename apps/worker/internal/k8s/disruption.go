@@ -0,0 +1,120 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ---------------------------------------------------------------------------
+// High availability
+// ---------------------------------------------------------------------------
+// Apps that can run more than one replica spread their pods across nodes
+// and zones, and get a PodDisruptionBudget so a node drain evicts them one
+// at a time. Spreading is best effort: a single-node cluster still
+// schedules every pod. The budget covers all pods of the app, including
+// both blue/green colors.
+// ---------------------------------------------------------------------------
+
+// highlyAvailable reports whether the app can run more than one replica.
+func (o DeployOptions) highlyAvailable() bool {
+	return o.Replicas > 1 || o.Scaling.MaxReplicas > 1
+}
+
+// spreadConstraints spreads the pods matched by selector over nodes, then
+// zones. Only pods of the same ReplicaSet are counted, so a rolling
+// update's surge pod isn't pushed away by the pods it replaces.
+func spreadConstraints(opts DeployOptions, selector map[string]string) []corev1.TopologySpreadConstraint {
+	if !opts.highlyAvailable() {
+		return nil
+	}
+
+	topologyKeys := []string{corev1.LabelHostname, corev1.LabelTopologyZone}
+
+	constraints := make([]corev1.TopologySpreadConstraint, 0, len(topologyKeys))
+	for _, key := range topologyKeys {
+		constraints = append(constraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       key,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: selector},
+			MatchLabelKeys:    []string{"pod-template-hash"},
+		})
+	}
+	return constraints
+}
+
+// applyDisruptionBudget creates or updates the app's PodDisruptionBudget,
+// or removes it when the app runs a single replica.
+func (c *Client) applyDisruptionBudget(ctx context.Context, opts DeployOptions) error {
+	name := opts.ResourceName()
+
+	if !opts.highlyAvailable() {
+		return c.DeleteDisruptionBudget(ctx, name)
+	}
+
+	labels := map[string]string{
+		"app":                          name,
+		"app.kubernetes.io/managed-by": "code2cloud",
+		"code2cloud/deployment-id":     opts.DeploymentID,
+		"code2cloud/project-id":        opts.ProjectID,
+	}
+	for k, v := range opts.environmentLabels() {
+		labels[k] = v
+	}
+
+	maxUnavailable := intstr.FromInt32(1)
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.namespace,
+			Labels:    labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": name},
+			},
+		},
+	}
+
+	c.logger.Info("Creating/updating disruption budget",
+		zap.String("name", name),
+	)
+
+	pdbClient := c.clientset.PolicyV1().PodDisruptionBudgets(c.namespace)
+
+	existing, err := pdbClient.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if _, err := pdbClient.Create(ctx, pdb, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create disruption budget: %w", err)
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to get disruption budget: %w", err)
+	}
+
+	pdb.ResourceVersion = existing.ResourceVersion
+	if _, err := pdbClient.Update(ctx, pdb, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update disruption budget: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteDisruptionBudget(ctx context.Context, name string) error {
+	name = sanitizeK8sName(name)
+
+	err := c.clientset.PolicyV1().PodDisruptionBudgets(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete disruption budget: %w", err)
+	}
+	return nil
+}
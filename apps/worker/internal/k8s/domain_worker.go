@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/metrics"
 )
 
 type DomainWorker struct {
@@ -119,6 +121,8 @@ func (dw *DomainWorker) verifyAndActivate(ctx context.Context, domain PendingDom
 	result := dw.manager.VerifyDNS(domain.Domain)
 
 	if !result.Verified {
+		metrics.DomainVerifications.WithLabelValues("pending").Inc()
+
 		dw.logger.Debug("DNS not verified yet",
 			zap.String("domain", domain.Domain),
 			zap.String("error", result.Error),
@@ -145,11 +149,15 @@ func (dw *DomainWorker) verifyAndActivate(ctx context.Context, domain PendingDom
 			zap.Error(err),
 		)
 
+		metrics.DomainVerifications.WithLabelValues("error").Inc()
+
 		dw.updateDomainStatus(ctx, domain.ID, "ERROR",
 			"DNS verified but failed to configure routing. Will retry.")
 		return
 	}
 
+	metrics.DomainVerifications.WithLabelValues("activated").Inc()
+
 	if err := dw.updateDomainStatus(ctx, domain.ID, "ACTIVE", ""); err != nil {
 		dw.logger.Warn("Failed to mark domain as active",
 			zap.String("domain", domain.Domain),
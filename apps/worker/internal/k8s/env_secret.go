@@ -0,0 +1,182 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ---------------------------------------------------------------------------
+// Environment secrets
+// ---------------------------------------------------------------------------
+// User env vars never appear in a pod spec. Each deploy writes them to a
+// new Secret, <deployment>-env-<deployment id>, which the pod template loads
+// through envFrom. A ReplicaSet therefore keeps the values it was deployed
+// with, so rollbacks and restarts of older pods see the right ones, and a
+// redeploy rotates them. Secrets no ReplicaSet references anymore are
+// pruned by the cleanup worker.
+// ---------------------------------------------------------------------------
+
+const (
+	envSecretLabel = "code2cloud/env-secret"
+
+	// Age before an unreferenced env Secret is pruned, covering the time
+	// between writing it and applying the Deployment that uses it
+	envSecretGrace = 10 * time.Minute
+)
+
+func envSecretName(deploymentName, deploymentID string) string {
+	id := sanitizeK8sName(deploymentID)
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return buildNameWithSuffix(deploymentName, "-env-"+id)
+}
+
+// applyEnvSecret writes the user env vars of a deploy to its Secret and
+// returns the Secret's name, or "" when there are none.
+func (c *Client) applyEnvSecret(ctx context.Context, opts DeployOptions, deploymentName string) (string, error) {
+	if len(opts.EnvVars) == 0 {
+		return "", nil
+	}
+
+	name := opts.ResourceName()
+	secretName := envSecretName(deploymentName, opts.DeploymentID)
+
+	labels := map[string]string{
+		"app":                          name,
+		"app.kubernetes.io/managed-by": "code2cloud",
+		"code2cloud/deployment-id":     opts.DeploymentID,
+		"code2cloud/project-id":        opts.ProjectID,
+		envSecretLabel:                 "true",
+	}
+	for k, v := range opts.environmentLabels() {
+		labels[k] = v
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: c.namespace,
+			Labels:    labels,
+		},
+		Type:       corev1.SecretTypeOpaque,
+		StringData: opts.EnvVars,
+	}
+
+	secretClient := c.clientset.CoreV1().Secrets(c.namespace)
+
+	existing, err := secretClient.Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if _, err := secretClient.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				return "", fmt.Errorf("failed to create env secret: %w", err)
+			}
+			return secretName, nil
+		}
+		return "", fmt.Errorf("failed to get env secret: %w", err)
+	}
+
+	// A retried deploy rewrites its own Secret
+	secret.ResourceVersion = existing.ResourceVersion
+	if _, err := secretClient.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to update env secret: %w", err)
+	}
+	return secretName, nil
+}
+
+func envFromSecret(secretName string) []corev1.EnvFromSource {
+	if secretName == "" {
+		return nil
+	}
+	return []corev1.EnvFromSource{{
+		SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+		},
+	}}
+}
+
+// PruneEnvSecrets deletes env Secrets that no Deployment or ReplicaSet
+// references anymore. It returns how many were deleted.
+func (c *Client) PruneEnvSecrets(ctx context.Context) (int, error) {
+	secrets, err := c.clientset.CoreV1().Secrets(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: envSecretLabel + "=true",
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list env secrets: %w", err)
+	}
+	if len(secrets.Items) == 0 {
+		return 0, nil
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: "app.kubernetes.io/managed-by=code2cloud"}
+
+	deployments, err := c.clientset.AppsV1().Deployments(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	replicaSets, err := c.clientset.AppsV1().ReplicaSets(c.namespace).List(ctx, listOptions)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list replica sets: %w", err)
+	}
+
+	referenced := make(map[string]bool)
+	addReferences := func(spec corev1.PodSpec) {
+		for _, container := range spec.Containers {
+			for _, source := range container.EnvFrom {
+				if source.SecretRef != nil {
+					referenced[source.SecretRef.Name] = true
+				}
+			}
+		}
+	}
+	for _, deployment := range deployments.Items {
+		addReferences(deployment.Spec.Template.Spec)
+	}
+	for _, rs := range replicaSets.Items {
+		addReferences(rs.Spec.Template.Spec)
+	}
+
+	pruned := 0
+	for _, secret := range secrets.Items {
+		if referenced[secret.Name] || time.Since(secret.CreationTimestamp.Time) < envSecretGrace {
+			continue
+		}
+
+		if err := c.clientset.CoreV1().Secrets(c.namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return pruned, fmt.Errorf("failed to delete env secret: %w", err)
+		}
+
+		c.logger.Info("Pruned env secret",
+			zap.String("secret", secret.Name),
+			zap.String("app", secret.Labels["app"]),
+		)
+		pruned++
+	}
+
+	return pruned, nil
+}
+
+// deleteEnvSecrets removes every env Secret of an app.
+func (c *Client) deleteEnvSecrets(ctx context.Context, name string) error {
+	secretClient := c.clientset.CoreV1().Secrets(c.namespace)
+
+	secrets, err := secretClient.List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s,%s=true", name, envSecretLabel),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list env secrets: %w", err)
+	}
+
+	for _, secret := range secrets.Items {
+		if err := secretClient.Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete env secret: %w", err)
+		}
+	}
+	return nil
+}
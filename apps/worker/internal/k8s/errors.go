@@ -0,0 +1,32 @@
+package k8s
+
+import (
+	"errors"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"code2cloud/worker/internal/types"
+)
+
+// classifyAPIError marks Kubernetes API failures that are worth retrying
+// (API server overloaded, timeouts, optimistic-lock conflicts, network).
+func classifyAPIError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var netErr net.Error
+	switch {
+	case apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err),
+		apierrors.IsTooManyRequests(err),
+		apierrors.IsServiceUnavailable(err),
+		apierrors.IsInternalError(err),
+		apierrors.IsConflict(err),
+		errors.As(err, &netErr):
+		return types.Transient(err)
+	}
+
+	return err
+}
@@ -0,0 +1,269 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"code2cloud/worker/internal/logging"
+)
+
+// ---------------------------------------------------------------------------
+// Kubernetes events → SYSTEM logs
+// ---------------------------------------------------------------------------
+// While a deployment rolls out and for as long as it is live, Events about
+// the app's Deployments, ReplicaSets and Pods (scheduling, image pulls,
+// probe failures, evictions...) are forwarded to its SYSTEM log. A single
+// namespace-wide watch, open while any deployment is subscribed, feeds
+// every deployment the worker follows. Container
+// restarts, which the kubelet doesn't report as events, are read from pod
+// status so OOM kills show up too.
+// ---------------------------------------------------------------------------
+
+// WatchEvents forwards Kubernetes events for appName to the deployment's
+// SYSTEM log until StopStreaming is called for it.
+func (ls *LogStreamer) WatchEvents(ctx context.Context, deploymentID, appName string) {
+	name := sanitizeK8sName(appName)
+	systemLog := ls.logFactory.CreateSystemLogger(deploymentID)
+	sub := &eventSub{app: name, log: systemLog}
+
+	ls.mu.Lock()
+	ls.stopEventsLocked(deploymentID)
+	watchCtx, cancel := context.WithCancel(ctx)
+	ls.activeEvents[deploymentID] = cancel
+	ls.eventSubs[deploymentID] = sub
+
+	// One Events watch per worker serves every deployment
+	if ls.stopEventWatch == nil {
+		eventsCtx, stop := context.WithCancel(context.Background())
+		ls.stopEventWatch = stop
+		go ls.followEvents(eventsCtx)
+	}
+	ls.mu.Unlock()
+
+	ls.logger.Info("Watching Kubernetes events",
+		zap.String("deployment", deploymentID),
+		zap.String("app", name),
+	)
+
+	go func() {
+		ls.followRestarts(watchCtx, name, systemLog)
+
+		ls.mu.Lock()
+		if ls.eventSubs[deploymentID] == sub {
+			ls.dropEventSubLocked(deploymentID)
+		}
+		ls.mu.Unlock()
+
+		systemLog.Close()
+	}()
+}
+
+// eventSub is a deployment's share of the Events watch.
+type eventSub struct {
+	app string
+	log *logging.StreamLogger
+}
+
+func (ls *LogStreamer) stopEventsLocked(deploymentID string) {
+	if cancel, exists := ls.activeEvents[deploymentID]; exists {
+		cancel()
+		delete(ls.activeEvents, deploymentID)
+	}
+	ls.dropEventSubLocked(deploymentID)
+}
+
+// dropEventSubLocked unsubscribes a deployment from events and closes the
+// watch once nobody is left on it.
+func (ls *LogStreamer) dropEventSubLocked(deploymentID string) {
+	delete(ls.eventSubs, deploymentID)
+
+	if len(ls.eventSubs) == 0 && ls.stopEventWatch != nil {
+		ls.stopEventWatch()
+		ls.stopEventWatch = nil
+	}
+}
+
+// followEvents watches the namespace's Events, starting from now, and logs
+// each one to the deployments of the app it is about.
+func (ls *LogStreamer) followEvents(ctx context.Context) {
+	events := ls.client.clientset.CoreV1().Events(ls.client.namespace)
+	resourceVersion := ""
+
+	ls.watchLoop(ctx, "events", func(ctx context.Context) (watch.Interface, error) {
+		if resourceVersion == "" {
+			list, err := events.List(ctx, metav1.ListOptions{Limit: 1})
+			if err != nil {
+				return nil, err
+			}
+			resourceVersion = list.ResourceVersion
+		}
+		return events.Watch(ctx, metav1.ListOptions{ResourceVersion: resourceVersion})
+	}, func(event watch.Event) {
+		if event.Type == watch.Error {
+			// Usually "resource version too old"; start again from now
+			resourceVersion = ""
+			return
+		}
+
+		kubeEvent, ok := event.Object.(*corev1.Event)
+		if !ok {
+			return
+		}
+		resourceVersion = kubeEvent.ResourceVersion
+
+		if event.Type == watch.Deleted {
+			return
+		}
+
+		ls.mu.Lock()
+		var targets []*logging.StreamLogger
+		for _, sub := range ls.eventSubs {
+			if belongsToApp(sub.app, kubeEvent.InvolvedObject) {
+				targets = append(targets, sub.log)
+			}
+		}
+		ls.mu.Unlock()
+
+		if len(targets) == 0 {
+			return
+		}
+		line := formatEvent(kubeEvent)
+		for _, systemLog := range targets {
+			systemLog.Log(line)
+		}
+	})
+}
+
+// followRestarts watches the app's pods and logs every container restart
+// with the reason and exit code of the previous run.
+func (ls *LogStreamer) followRestarts(ctx context.Context, appName string, systemLog *logging.StreamLogger) {
+	pods := ls.client.clientset.CoreV1().Pods(ls.client.namespace)
+	restarts := make(map[string]int32)
+
+	ls.watchLoop(ctx, "pods", func(ctx context.Context) (watch.Interface, error) {
+		return pods.Watch(ctx, metav1.ListOptions{LabelSelector: "app=" + appName})
+	}, func(event watch.Event) {
+		pod, ok := event.Object.(*corev1.Pod)
+		if !ok {
+			return
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			key := pod.Name + "/" + status.Name
+			seen, known := restarts[key]
+			restarts[key] = status.RestartCount
+
+			// The first sighting of a pod (including every reconnect)
+			// only records its count
+			if !known || status.RestartCount <= seen {
+				continue
+			}
+
+			line := fmt.Sprintf("⚠ [Pod %s] Container %s restarted (restart #%d)",
+				shortPodName(pod.Name), status.Name, status.RestartCount)
+			if last := status.LastTerminationState.Terminated; last != nil {
+				line = fmt.Sprintf("⚠ [Pod %s] Container %s restarted after %s, exit code %d (restart #%d)",
+					shortPodName(pod.Name), status.Name, last.Reason, last.ExitCode, status.RestartCount)
+			}
+			systemLog.Log(line)
+		}
+
+		if event.Type == watch.Deleted {
+			for _, status := range pod.Status.ContainerStatuses {
+				delete(restarts, pod.Name+"/"+status.Name)
+			}
+		}
+	})
+}
+
+// watchLoop keeps a watch open until ctx is done, reopening it when the
+// API server closes it and backing off after errors.
+func (ls *LogStreamer) watchLoop(ctx context.Context, what string, open func(context.Context) (watch.Interface, error), handle func(watch.Event)) {
+	for ctx.Err() == nil {
+		watcher, err := open(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if !apierrors.IsForbidden(err) {
+				ls.logger.Warn("Failed to watch, retrying in 5s...",
+					zap.String("resource", what),
+					zap.Error(err),
+				)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for event := range watcher.ResultChan() {
+			handle(event)
+		}
+		watcher.Stop()
+	}
+}
+
+// belongsToApp matches an event's object against the names the app's
+// objects get: the Deployment (or its blue/green colors), ReplicaSets named
+// <deployment>-<hash> and Pods named <replicaset>-<suffix>. Matching whole
+// names keeps "shop" from picking up events of "shop-admin".
+func belongsToApp(appName string, object corev1.ObjectReference) bool {
+	var deploymentName string
+	switch object.Kind {
+	case "Deployment":
+		deploymentName = object.Name
+	case "ReplicaSet":
+		deploymentName = trimNameSegments(object.Name, 1)
+	case "Pod":
+		deploymentName = trimNameSegments(object.Name, 2)
+	default:
+		return false
+	}
+
+	return deploymentName == appName ||
+		deploymentName == colorDeploymentName(appName, colorBlue) ||
+		deploymentName == colorDeploymentName(appName, colorGreen)
+}
+
+// trimNameSegments drops the last n dash-separated segments of a name.
+func trimNameSegments(name string, n int) string {
+	for i := 0; i < n; i++ {
+		idx := strings.LastIndex(name, "-")
+		if idx < 0 {
+			return ""
+		}
+		name = name[:idx]
+	}
+	return name
+}
+
+// formatEvent renders an event as "⚠ [Pod 5q8tk] BackOff: message (x3)".
+func formatEvent(event *corev1.Event) string {
+	object := event.InvolvedObject.Name
+	if event.InvolvedObject.Kind == "Pod" {
+		object = shortPodName(object)
+	}
+
+	line := fmt.Sprintf("[%s %s] %s: %s",
+		event.InvolvedObject.Kind, object, event.Reason, strings.TrimSpace(event.Message))
+
+	if event.Count > 1 {
+		line += fmt.Sprintf(" (x%d)", event.Count)
+	}
+	if event.Type == corev1.EventTypeWarning {
+		line = "⚠ " + line
+	}
+	return line
+}
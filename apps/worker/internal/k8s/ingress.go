@@ -14,13 +14,20 @@ import (
 const wildcardTLSSecret = "preview-wildcard-tls"
 
 func (c *Client) CreateOrUpdateIngress(ctx context.Context, opts DeployOptions) ([]string, error) {
-	name := sanitizeK8sName(opts.ProjectName)
+	name := opts.ResourceName()
+
+	// Previews answer only on their own branch host; custom domains
+	// always belong to production.
+	domains := opts.Domains
+	if opts.isPreview() {
+		domains = []string{c.previewHost(name)}
+	}
 
 	var subdomainHosts []string
 	var customHosts []string
-	allHosts := make([]string, 0, len(opts.Domains))
+	allHosts := make([]string, 0, len(domains))
 
-	for _, domain := range opts.Domains {
+	for _, domain := range domains {
 		if domain == "" {
 			continue
 		}
@@ -46,6 +53,9 @@ func (c *Client) CreateOrUpdateIngress(ctx context.Context, opts DeployOptions)
 		"app.kubernetes.io/managed-by": "code2cloud",
 		"code2cloud/project-id":        opts.ProjectID,
 	}
+	for k, v := range opts.environmentLabels() {
+		labels[k] = v
+	}
 
 	rules := buildIngressRules(allHosts, name)
 	tls := buildTLSEntries(name, subdomainHosts, customHosts)
@@ -147,7 +157,8 @@ func (c *Client) UpdateIngressHosts(ctx context.Context, name string, hosts []st
 		}
 	}
 
-	ingress.Spec.Rules = buildIngressRules(hosts, name)
+	// A sleeping app stays routed to the activator
+	ingress.Spec.Rules = buildIngressRules(hosts, ingressServiceName(ingress.Spec.Rules, name))
 	ingress.Spec.TLS = buildTLSEntries(name, subdomainHosts, customHosts)
 
 	if ingress.Annotations == nil {
@@ -176,6 +187,14 @@ func (c *Client) UpdateIngressHosts(ctx context.Context, name string, hosts []st
 // Helpers
 // ─────────────────────────────────────────────────────────────
 
+// previewHost is the hostname of a preview environment: its resource name
+// as a single label under the base domain, so the wildcard cert covers it.
+//
+// "my-app-feature-login-3f2a1c" → "my-app-feature-login-3f2a1c.preview.code2cloud.lakshman.me"
+func (c *Client) previewHost(name string) string {
+	return name + "." + c.baseDomain
+}
+
 // isSubdomain checks if host is under the platform's base domain.
 // c.baseDomain = "preview.code2cloud.lakshman.me"
 //
@@ -221,6 +240,22 @@ func buildIngressRules(hosts []string, serviceName string) []networkingv1.Ingres
 	return rules
 }
 
+// ingressServiceName returns the Service an Ingress's rules route to, or
+// fallback when they have none.
+func ingressServiceName(rules []networkingv1.IngressRule, fallback string) string {
+	for _, rule := range rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				return path.Backend.Service.Name
+			}
+		}
+	}
+	return fallback
+}
+
 func buildTLSEntries(name string, subdomainHosts, customHosts []string) []networkingv1.IngressTLS {
 	var tls []networkingv1.IngressTLS
 
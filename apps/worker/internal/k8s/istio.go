@@ -16,7 +16,7 @@ import (
 // ---------------------------------------------------------------------------
 
 func (c *Client) CreateOrUpdateServiceAccount(ctx context.Context, opts DeployOptions) error {
-	name := sanitizeK8sName(opts.ProjectName)
+	name := opts.ResourceName()
 	serviceAccountName := buildServiceAccountName(name)
 
 	labels := map[string]string{
@@ -26,6 +26,9 @@ func (c *Client) CreateOrUpdateServiceAccount(ctx context.Context, opts DeployOp
 		"code2cloud/deployment-id":     opts.DeploymentID,
 		"code2cloud/project-id":        opts.ProjectID,
 	}
+	for k, v := range opts.environmentLabels() {
+		labels[k] = v
+	}
 	for k, v := range opts.Labels {
 		labels[k] = v
 	}
@@ -83,7 +86,7 @@ func (c *Client) DeleteServiceAccount(ctx context.Context, appName string) error
 // ---------------------------------------------------------------------------
 
 func (c *Client) CreateOrUpdateNetworkPolicy(ctx context.Context, opts DeployOptions) error {
-	name := sanitizeK8sName(opts.ProjectName)
+	name := opts.ResourceName()
 	policyName := buildPolicyName(name)
 
 	labels := map[string]string{
@@ -92,6 +95,9 @@ func (c *Client) CreateOrUpdateNetworkPolicy(ctx context.Context, opts DeployOpt
 		"code2cloud/deployment-id":     opts.DeploymentID,
 		"code2cloud/project-id":        opts.ProjectID,
 	}
+	for k, v := range opts.environmentLabels() {
+		labels[k] = v
+	}
 
 	policy := &networkingv1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -134,6 +140,24 @@ func (c *Client) CreateOrUpdateNetworkPolicy(ctx context.Context, opts DeployOpt
 						},
 					},
 				},
+				{
+					// Rule 3: allow from the worker's activator, which
+					// proxies the requests that wake a sleeping app
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									"app.kubernetes.io/name": "code2cloud",
+								},
+							},
+							PodSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									"app": "worker",
+								},
+							},
+						},
+					},
+				},
 			},
 		},
 	}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/metrics"
 )
 
 type LogCleanupWorker struct {
@@ -72,9 +74,12 @@ func (lc *LogCleanupWorker) run(ctx context.Context) {
 
 	count, err := lc.triggerCleanup(ctx)
 	if err != nil {
+		metrics.CleanupRuns.WithLabelValues("logs", "error").Inc()
 		lc.logger.Warn("Log cleanup call failed", zap.Error(err))
 		return
 	}
 
+	metrics.CleanupRuns.WithLabelValues("logs", "success").Inc()
+
 	lc.logger.Info("Log cleanup completed ✅", zap.Int("deleted_count", count))
 }
\ No newline at end of file
@@ -22,6 +22,11 @@ type LogStreamer struct {
 	logger     *zap.Logger
 	mu            sync.Mutex
 	activeStreams map[string]context.CancelFunc
+	activeEvents  map[string]context.CancelFunc
+
+	// Deployments following the shared Events watch, and how to stop it
+	eventSubs      map[string]*eventSub
+	stopEventWatch context.CancelFunc
 }
 
 func NewLogStreamer(client *Client, logFactory *logging.Factory, logger *zap.Logger) *LogStreamer {
@@ -30,11 +35,15 @@ func NewLogStreamer(client *Client, logFactory *logging.Factory, logger *zap.Log
 		logFactory:    logFactory,
 		logger:        logger,
 		activeStreams:  map[string]context.CancelFunc{},
+		activeEvents:   map[string]context.CancelFunc{},
+		eventSubs:      map[string]*eventSub{},
 	}
 }
 
-func (ls *LogStreamer) StartStreaming(ctx context.Context, deploymentID, projectName string) error {
-	name := sanitizeK8sName(projectName)
+// StartStreaming streams runtime logs from the deployment's pods of
+// appName (DeployResult.AppName).
+func (ls *LogStreamer) StartStreaming(ctx context.Context, deploymentID, appName string) error {
+	name := sanitizeK8sName(appName)
 
 	ls.mu.Lock()
 	if cancel, exists := ls.activeStreams[deploymentID]; exists {
@@ -47,7 +56,8 @@ func (ls *LogStreamer) StartStreaming(ctx context.Context, deploymentID, project
 	ls.mu.Unlock()
 
 	pods, err := ls.client.clientset.CoreV1().Pods(ls.client.namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("app=%s", name),
+		// Scoped to this deployment so an idle blue/green color isn't streamed
+		LabelSelector: fmt.Sprintf("app=%s,code2cloud/deployment-id=%s", name, deploymentID),
 	})
 	if err != nil {
 		cancel()
@@ -171,11 +181,13 @@ func (ls *LogStreamer) streamOnce(
 	return nil
 }
 
-
+// StopStreaming ends a deployment's runtime log stream and event watch.
 func (ls *LogStreamer) StopStreaming(deploymentID string) {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
 
+	ls.stopEventsLocked(deploymentID)
+
 	if cancel, exists := ls.activeStreams[deploymentID]; exists {
 		cancel()
 		delete(ls.activeStreams, deploymentID)
@@ -197,6 +209,10 @@ func (ls *LogStreamer) StopAll() {
 	}
 
 	ls.activeStreams = make(map[string]context.CancelFunc)
+
+	for id := range ls.activeEvents {
+		ls.stopEventsLocked(id)
+	}
 	ls.logger.Info("All log streams stopped")
 }
 
@@ -0,0 +1,120 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"code2cloud/worker/internal/types"
+)
+
+// startupProbePeriod is how often the startup probe runs; its failure
+// threshold is derived from the configured startup timeout.
+const startupProbePeriod = 5
+
+type containerProbes struct {
+	liveness  *corev1.Probe
+	readiness *corev1.Probe
+	startup   *corev1.Probe
+}
+
+// buildProbes turns the deploy's health check into container probes. Apps
+// without a health path, or whose expected status the kubelet can't check,
+// get TCP probes on their port.
+func buildProbes(opts DeployOptions) containerProbes {
+	check := opts.HealthCheck
+	handler := probeHandler(opts)
+
+	liveness := &corev1.Probe{
+		ProbeHandler:        handler,
+		InitialDelaySeconds: 10,
+		PeriodSeconds:       30,
+		TimeoutSeconds:      5,
+		FailureThreshold:    3,
+	}
+
+	readiness := &corev1.Probe{
+		ProbeHandler:        handler,
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       10,
+		TimeoutSeconds:      3,
+		FailureThreshold:    3,
+	}
+
+	for _, probe := range []*corev1.Probe{liveness, readiness} {
+		if check.InitialDelaySeconds > 0 {
+			probe.InitialDelaySeconds = check.InitialDelaySeconds
+		}
+		if check.PeriodSeconds > 0 {
+			probe.PeriodSeconds = check.PeriodSeconds
+		}
+		if check.TimeoutSeconds > 0 {
+			probe.TimeoutSeconds = check.TimeoutSeconds
+		}
+		if check.FailureThreshold > 0 {
+			probe.FailureThreshold = check.FailureThreshold
+		}
+	}
+
+	probes := containerProbes{liveness: liveness, readiness: readiness}
+
+	// The startup probe holds off the others until the app first answers,
+	// so their initial delays are no longer needed
+	if check.StartupTimeoutSeconds > 0 {
+		probes.startup = &corev1.Probe{
+			ProbeHandler:     handler,
+			PeriodSeconds:    startupProbePeriod,
+			TimeoutSeconds:   readiness.TimeoutSeconds,
+			FailureThreshold: (check.StartupTimeoutSeconds + startupProbePeriod - 1) / startupProbePeriod,
+		}
+		liveness.InitialDelaySeconds = 0
+		readiness.InitialDelaySeconds = 0
+	}
+
+	return probes
+}
+
+func probeHandler(opts DeployOptions) corev1.ProbeHandler {
+	check := opts.HealthCheck
+
+	if check.Path == "" || !kubeletAcceptsStatus(check.ExpectedStatus) {
+		return corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt32(opts.Port),
+			},
+		}
+	}
+
+	return corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path: check.Path,
+			Port: intstr.FromInt32(opts.Port),
+		},
+	}
+}
+
+// kubeletAcceptsStatus reports whether an HTTP probe can check for status.
+// The kubelet treats any 2xx or 3xx as success; other expected statuses
+// are only verified by the worker when a rollout finishes.
+func kubeletAcceptsStatus(status int) bool {
+	return status == 0 || (status >= 200 && status < 400)
+}
+
+// healthCheckPasses judges a response from the worker's own HTTP check.
+// Without a configured path any answer below 500 means the app is up.
+func healthCheckPasses(check types.HealthCheck, code int) bool {
+	switch {
+	case check.ExpectedStatus != 0:
+		return code == check.ExpectedStatus
+	case check.Path != "":
+		return code >= 200 && code < 400
+	default:
+		return code > 0 && code < 500
+	}
+}
+
+func healthCheckPath(check types.HealthCheck) string {
+	if check.Path == "" {
+		return "/"
+	}
+	return check.Path
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"code2cloud/worker/internal/metrics"
 	"code2cloud/worker/internal/types"
 
 	"go.uber.org/zap"
@@ -15,7 +16,8 @@ type ProjectCleanupWorker struct {
 	logStreamer *LogStreamer
 	logger     *zap.Logger
 
-	fetchCleanupJobs func(ctx context.Context) (*types.ProjectCleanupJob, error)
+	fetchCleanupJobs        func(ctx context.Context) (*types.ProjectCleanupJob, error)
+	fetchPreviewCleanupJobs func(ctx context.Context) (*types.PreviewCleanupJob, error)
 
 	checkInterval time.Duration
 	wg            sync.WaitGroup
@@ -27,6 +29,9 @@ type ProjectCleanupWorkerConfig struct {
 	Logger           *zap.Logger
 	FetchCleanupJobs func(ctx context.Context) (*types.ProjectCleanupJob, error)
 	CheckInterval    time.Duration
+
+	// Optional: closed pull requests whose preview should be torn down
+	FetchPreviewCleanupJobs func(ctx context.Context) (*types.PreviewCleanupJob, error)
 }
 
 func NewProjectCleanupWorker(config ProjectCleanupWorkerConfig) *ProjectCleanupWorker {
@@ -39,8 +44,9 @@ func NewProjectCleanupWorker(config ProjectCleanupWorkerConfig) *ProjectCleanupW
 		client:           config.Client,
 		logStreamer:       config.LogStreamer,
 		logger:           config.Logger,
-		fetchCleanupJobs: config.FetchCleanupJobs,
-		checkInterval:    interval,
+		fetchCleanupJobs:        config.FetchCleanupJobs,
+		fetchPreviewCleanupJobs: config.FetchPreviewCleanupJobs,
+		checkInterval:           interval,
 	}
 }
 
@@ -61,6 +67,7 @@ func (pw *ProjectCleanupWorker) Start(ctx context.Context) {
 				return
 			default:
 				pw.processNextJob(ctx)
+				pw.processNextPreviewJob(ctx)
 			}
 
 			select {
@@ -105,18 +112,86 @@ func (pw *ProjectCleanupWorker) cleanupProject(ctx context.Context, job *types.P
 		}
 	}
 
-	if err := pw.client.Cleanup(ctx, CleanupOptions{
+	cleanupErr := pw.client.Cleanup(ctx, CleanupOptions{
 		ProjectName: job.ProjectName,
-	}); err != nil {
+	})
+	if cleanupErr != nil {
 		pw.logger.Warn("Partial cleanup failure for project deletion",
 			zap.String("project", job.ProjectName),
-			zap.Error(err),
+			zap.Error(cleanupErr),
+		)
+	}
+
+	// Step 2: Remove any preview environments the project still has
+	previewErr := pw.client.CleanupPreviews(ctx, job.ProjectID)
+	if previewErr != nil {
+		pw.logger.Warn("Partial preview cleanup failure for project deletion",
+			zap.String("project", job.ProjectName),
+			zap.Error(previewErr),
 		)
 	}
 
+	if cleanupErr != nil || previewErr != nil {
+		metrics.CleanupRuns.WithLabelValues("project", "error").Inc()
+	} else {
+		metrics.CleanupRuns.WithLabelValues("project", "success").Inc()
+	}
+
 	pw.logger.Info("Project cleanup complete 🗑️",
 		zap.String("project_id", job.ProjectID),
 		zap.String("project_name", job.ProjectName),
 		zap.String("k8s_name", name),
 	)
-}
\ No newline at end of file
+}
+
+func (pw *ProjectCleanupWorker) processNextPreviewJob(ctx context.Context) {
+	if ctx.Err() != nil || pw.fetchPreviewCleanupJobs == nil {
+		return
+	}
+
+	job, err := pw.fetchPreviewCleanupJobs(ctx)
+	if err != nil || job == nil {
+		return
+	}
+
+	pw.logger.Info("Processing preview cleanup",
+		zap.String("project_id", job.ProjectID),
+		zap.String("project_name", job.ProjectName),
+		zap.String("branch", job.Branch),
+		zap.Int("deployments", len(job.DeploymentIDs)),
+	)
+
+	pw.cleanupPreview(ctx, job)
+}
+
+func (pw *ProjectCleanupWorker) cleanupPreview(ctx context.Context, job *types.PreviewCleanupJob) {
+	for _, deploymentID := range job.DeploymentIDs {
+		if pw.logStreamer != nil {
+			pw.logStreamer.StopStreaming(deploymentID)
+		}
+	}
+
+	opts := CleanupOptions{
+		ProjectName: job.ProjectName,
+		Environment: types.EnvironmentPreview,
+		Branch:      job.Branch,
+	}
+
+	if err := pw.client.Cleanup(ctx, opts); err != nil {
+		metrics.CleanupRuns.WithLabelValues("preview", "error").Inc()
+		pw.logger.Warn("Partial cleanup failure for preview",
+			zap.String("project", job.ProjectName),
+			zap.String("branch", job.Branch),
+			zap.Error(err),
+		)
+		return
+	}
+
+	metrics.CleanupRuns.WithLabelValues("preview", "success").Inc()
+
+	pw.logger.Info("Preview cleanup complete 🧹",
+		zap.String("project_id", job.ProjectID),
+		zap.String("branch", job.Branch),
+		zap.String("k8s_name", ResourceName(job.ProjectName, opts.Environment, job.Branch)),
+	)
+}
@@ -0,0 +1,316 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"code2cloud/worker/internal/logging"
+)
+
+// Reasons reported in ReadinessError.Reason besides the kubelet's own
+// waiting reasons (CrashLoopBackOff, ImagePullBackOff, ...)
+const (
+	ReasonOOMKilled     = "OOMKilled"
+	ReasonProbeFailed   = "ProbeFailed"
+	ReasonUnschedulable = "Unschedulable"
+	ReasonTimeout       = "Timeout"
+)
+
+// Waiting reasons that won't resolve without a new deploy. ErrImagePull
+// isn't one: the kubelet retries it and reports ImagePullBackOff if it
+// keeps failing.
+var fatalWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// ReadinessError explains why a deployment's pods never became ready.
+type ReadinessError struct {
+	Deployment string
+	Pod        string
+	Container  string
+	Reason     string
+
+	// Kubelet or probe message for the current state
+	Detail string
+
+	// The container's last termination message (or log tail) and exit
+	// code; empty and -1 when it never terminated
+	TerminationMessage string
+	ExitCode           int32
+}
+
+func (e *ReadinessError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "deployment %s not ready: %s", e.Deployment, e.Reason)
+	if e.Container != "" {
+		fmt.Fprintf(&b, " (pod %s, container %s", e.Pod, e.Container)
+		if e.ExitCode >= 0 {
+			fmt.Fprintf(&b, ", exit code %d", e.ExitCode)
+		}
+		b.WriteString(")")
+	}
+	if e.Detail != "" {
+		fmt.Fprintf(&b, ": %s", e.Detail)
+	}
+	return b.String()
+}
+
+// podFailure looks for pods of the deployment's current template that are
+// stuck in a state they won't recover from.
+func (c *Client) podFailure(ctx context.Context, deployment *appsv1.Deployment) *ReadinessError {
+	pods, err := c.currentPods(ctx, deployment)
+	if err != nil {
+		c.logger.Debug("Failed to list pods", zap.Error(err))
+		return nil
+	}
+
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			failure := containerFailure(deployment.Name, pod.Name, status)
+			if failure != nil {
+				return failure
+			}
+		}
+	}
+	return nil
+}
+
+func containerFailure(deploymentName, podName string, status corev1.ContainerStatus) *ReadinessError {
+	failure := &ReadinessError{
+		Deployment: deploymentName,
+		Pod:        podName,
+		Container:  status.Name,
+		ExitCode:   -1,
+	}
+
+	if last := status.LastTerminationState.Terminated; last != nil {
+		failure.TerminationMessage = strings.TrimSpace(last.Message)
+		failure.ExitCode = last.ExitCode
+	}
+
+	switch {
+	case status.State.Terminated != nil && status.State.Terminated.Reason == ReasonOOMKilled:
+		failure.Reason = ReasonOOMKilled
+		failure.TerminationMessage = strings.TrimSpace(status.State.Terminated.Message)
+		failure.ExitCode = status.State.Terminated.ExitCode
+		failure.Detail = "container exceeded its memory limit"
+		return failure
+
+	case status.State.Waiting != nil && fatalWaitingReasons[status.State.Waiting.Reason]:
+		failure.Reason = status.State.Waiting.Reason
+		failure.Detail = status.State.Waiting.Message
+
+		// A crash loop caused by the memory limit is reported as such
+		last := status.LastTerminationState.Terminated
+		if failure.Reason == "CrashLoopBackOff" && last != nil && last.Reason == ReasonOOMKilled {
+			failure.Reason = ReasonOOMKilled
+			failure.Detail = "container exceeded its memory limit"
+		}
+		return failure
+	}
+
+	return nil
+}
+
+// diagnoseNotReady explains a readiness timeout: failing probes, pods that
+// can't be scheduled, or simply slow pods.
+func (c *Client) diagnoseNotReady(ctx context.Context, name string, deployment *appsv1.Deployment) *ReadinessError {
+	failure := &ReadinessError{
+		Deployment: name,
+		Reason:     ReasonTimeout,
+		Detail:     "pods did not become ready in time",
+		ExitCode:   -1,
+	}
+	if deployment == nil {
+		return failure
+	}
+
+	if podFailure := c.podFailure(ctx, deployment); podFailure != nil {
+		return podFailure
+	}
+
+	pods, err := c.currentPods(ctx, deployment)
+	if err != nil {
+		return failure
+	}
+
+	for _, pod := range pods {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled &&
+				condition.Status == corev1.ConditionFalse &&
+				condition.Reason == corev1.PodReasonUnschedulable {
+				failure.Pod = pod.Name
+				failure.Reason = ReasonUnschedulable
+				failure.Detail = condition.Message
+				return failure
+			}
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Ready || status.State.Running == nil {
+				continue
+			}
+
+			failure.Pod = pod.Name
+			failure.Container = status.Name
+			if last := status.LastTerminationState.Terminated; last != nil {
+				failure.TerminationMessage = strings.TrimSpace(last.Message)
+				failure.ExitCode = last.ExitCode
+			}
+
+			if message := c.lastProbeFailure(ctx, pod.Name); message != "" {
+				failure.Reason = ReasonProbeFailed
+				failure.Detail = message
+			}
+			return failure
+		}
+	}
+
+	return failure
+}
+
+// lastProbeFailure returns the most recent failed-probe event of a pod.
+func (c *Client) lastProbeFailure(ctx context.Context, podName string) string {
+	events, err := c.clientset.CoreV1().Events(c.namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{
+			"involvedObject.kind": "Pod",
+			"involvedObject.name": podName,
+			"reason":              "Unhealthy",
+		}.String(),
+	})
+	if err != nil || len(events.Items) == 0 {
+		return ""
+	}
+
+	latest := events.Items[0]
+	for _, event := range events.Items[1:] {
+		if event.LastTimestamp.After(latest.LastTimestamp.Time) {
+			latest = event
+		}
+	}
+	return latest.Message
+}
+
+// currentPods lists the pods created from the deployment's current
+// template. Pods of the previous version are still running during a
+// rolling update and say nothing about the new one.
+func (c *Client) currentPods(ctx context.Context, deployment *appsv1.Deployment) ([]corev1.Pod, error) {
+	labels := deployment.Spec.Template.Labels
+	selector := fmt.Sprintf("app=%s", labels["app"])
+	if id := labels["code2cloud/deployment-id"]; id != "" {
+		selector += ",code2cloud/deployment-id=" + id
+	}
+	if color := labels[colorLabel]; color != "" {
+		selector += fmt.Sprintf(",%s=%s", colorLabel, color)
+	}
+
+	pods, err := c.clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	return pods.Items, nil
+}
+
+// RollbackDeployment points a Deployment back at the pod template of its
+// previous ReplicaSet, like `kubectl rollout undo`. It reports false when
+// there is no earlier revision to return to.
+func (c *Client) RollbackDeployment(ctx context.Context, name string) (bool, error) {
+	deployments := c.clientset.AppsV1().Deployments(c.namespace)
+
+	deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid deployment selector: %w", err)
+	}
+
+	replicaSets, err := c.clientset.AppsV1().ReplicaSets(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list replica sets: %w", err)
+	}
+
+	var owned []appsv1.ReplicaSet
+	for _, rs := range replicaSets.Items {
+		if metav1.IsControlledBy(&rs, deployment) {
+			owned = append(owned, rs)
+		}
+	}
+	if len(owned) < 2 {
+		return false, nil
+	}
+
+	sort.Slice(owned, func(i, j int) bool {
+		return replicaSetRevision(owned[i]) > replicaSetRevision(owned[j])
+	})
+	previous := owned[1]
+
+	template := previous.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+
+	deployment.Spec.Template = *template
+	if _, err := deployments.Update(ctx, deployment, metav1.UpdateOptions{}); err != nil {
+		return false, fmt.Errorf("failed to roll back deployment: %w", err)
+	}
+
+	c.logger.Info("Deployment rolled back",
+		zap.String("name", name),
+		zap.String("replica_set", previous.Name),
+		zap.Int64("revision", replicaSetRevision(previous)),
+	)
+	return true, nil
+}
+
+func replicaSetRevision(rs appsv1.ReplicaSet) int64 {
+	revision, _ := strconv.ParseInt(rs.Annotations["deployment.kubernetes.io/revision"], 10, 64)
+	return revision
+}
+
+// logNotReady writes why pods never became ready to the build log.
+func logNotReady(deployLog *logging.StreamLogger, err error) {
+	var failure *ReadinessError
+	if !errors.As(err, &failure) {
+		deployLog.Log(fmt.Sprintf("✗ Pods never became ready: %v", err))
+		return
+	}
+
+	deployLog.Log(fmt.Sprintf("✗ Pods never became ready: %s", failure.Reason))
+	if failure.Pod != "" {
+		deployLog.Log(fmt.Sprintf("  Pod:       %s", failure.Pod))
+	}
+	if failure.Container != "" {
+		deployLog.Log(fmt.Sprintf("  Container: %s", failure.Container))
+	}
+	if failure.ExitCode >= 0 {
+		deployLog.Log(fmt.Sprintf("  Exit code: %d", failure.ExitCode))
+	}
+	if failure.Detail != "" {
+		deployLog.Log(fmt.Sprintf("  Detail:    %s", failure.Detail))
+	}
+	if failure.TerminationMessage != "" {
+		deployLog.Log("  Last termination message:")
+		for _, line := range strings.Split(failure.TerminationMessage, "\n") {
+			deployLog.Log("    " + line)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/types"
+)
+
+// ReplicaReporter periodically sends the replica counts of live deployments
+// to the API, so autoscaling is visible in the dashboard. Only counts that
+// changed since the last report are sent.
+type ReplicaReporter struct {
+	client *Client
+	logger *zap.Logger
+
+	reportReplicas func(ctx context.Context, counts []types.ReplicaCount) error
+
+	checkInterval time.Duration
+	wg            sync.WaitGroup
+
+	// Last reported counts by deployment ID
+	reported map[string]types.ReplicaCount
+}
+
+type ReplicaReporterConfig struct {
+	Client         *Client
+	ReportReplicas func(ctx context.Context, counts []types.ReplicaCount) error
+	CheckInterval  time.Duration
+	Logger         *zap.Logger
+}
+
+func NewReplicaReporter(config ReplicaReporterConfig) *ReplicaReporter {
+	interval := config.CheckInterval
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+
+	return &ReplicaReporter{
+		client:         config.Client,
+		logger:         config.Logger,
+		reportReplicas: config.ReportReplicas,
+		checkInterval:  interval,
+		reported:       make(map[string]types.ReplicaCount),
+	}
+}
+
+func (rr *ReplicaReporter) Start(ctx context.Context) {
+	rr.wg.Add(1)
+
+	go func() {
+		defer rr.wg.Done()
+
+		rr.logger.Info("Replica reporter started",
+			zap.Duration("check_interval", rr.checkInterval),
+		)
+
+		ticker := time.NewTicker(rr.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				rr.logger.Info("Replica reporter stopped")
+				return
+			case <-ticker.C:
+				rr.report(ctx)
+			}
+		}
+	}()
+}
+
+func (rr *ReplicaReporter) Stop() {
+	rr.wg.Wait()
+}
+
+func (rr *ReplicaReporter) report(ctx context.Context) {
+	counts, err := rr.client.ReplicaCounts(ctx)
+	if err != nil {
+		rr.logger.Warn("Failed to read replica counts", zap.Error(err))
+		return
+	}
+
+	current := make(map[string]types.ReplicaCount, len(counts))
+	var changed []types.ReplicaCount
+	for _, count := range counts {
+		current[count.DeploymentID] = count
+		if rr.reported[count.DeploymentID] != count {
+			changed = append(changed, count)
+		}
+	}
+
+	if len(changed) == 0 {
+		rr.reported = current
+		return
+	}
+
+	if err := rr.reportReplicas(ctx, changed); err != nil {
+		rr.logger.Warn("Failed to report replica counts", zap.Error(err))
+		return
+	}
+
+	rr.reported = current
+}
@@ -13,7 +13,13 @@ import (
 
 
 func (c *Client) CreateOrUpdateService(ctx context.Context, opts DeployOptions) error {
-	name := sanitizeK8sName(opts.ProjectName)
+	return c.applyService(ctx, opts, map[string]string{"app": opts.ResourceName()})
+}
+
+// applyService creates or updates the app's Service routing to the pods
+// matched by selector. A single update swaps the selector atomically.
+func (c *Client) applyService(ctx context.Context, opts DeployOptions, selector map[string]string) error {
+	name := opts.ResourceName()
 
 	c.logger.Info("Creating/updating service",
 		zap.String("name", name),
@@ -26,19 +32,25 @@ func (c *Client) CreateOrUpdateService(ctx context.Context, opts DeployOptions)
 		"app.kubernetes.io/managed-by": "code2cloud",
 		"code2cloud/project-id":        opts.ProjectID,
 	}
+	for k, v := range opts.environmentLabels() {
+		labels[k] = v
+	}
 
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: c.namespace,
 			Labels:    labels,
+			Annotations: map[string]string{
+				// Send Traefik through the ClusterIP so its sidecar applies
+				// canary traffic splits instead of picking pods itself
+				"traefik.ingress.kubernetes.io/service.nativelb": "true",
+			},
 		},
 		Spec: corev1.ServiceSpec{
 			Type: corev1.ServiceTypeClusterIP,
 
-			Selector: map[string]string{
-				"app": name,
-			},
+			Selector: selector,
 
 			Ports: []corev1.ServicePort{{
 				Name:       "http",
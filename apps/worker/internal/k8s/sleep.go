@@ -0,0 +1,206 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// ---------------------------------------------------------------------------
+// Scale to zero
+// ---------------------------------------------------------------------------
+// An idle app keeps its Service and Ingress but runs no pods. Its Ingress
+// routes to the activator Service instead, an ExternalName for the worker's
+// activator, which scales the app back up on the first request and points
+// the Ingress back at the app once its pods are ready. The replica count to
+// restore is kept in an annotation on each Deployment. An autoscaler stays
+// in place: it doesn't act on a Deployment scaled to zero.
+// ---------------------------------------------------------------------------
+
+const (
+	sleepReplicasAnnotation = "code2cloud/sleep-replicas"
+
+	// ExternalName Service in the deployments namespace pointing at the
+	// worker's activator (see infra worker.yaml)
+	activatorServiceName = "code2cloud-activator"
+)
+
+// ScaleToZero routes an app's Ingress to the activator and scales its
+// Deployments to zero.
+func (c *Client) ScaleToZero(ctx context.Context, name string) error {
+	name = sanitizeK8sName(name)
+
+	deployments, err := c.appDeployments(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	// Requests reach the activator before the pods go away
+	if err := c.routeIngress(ctx, name, activatorServiceName); err != nil {
+		return err
+	}
+
+	for _, deployment := range deployments {
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		if replicas == 0 {
+			continue
+		}
+
+		// A color waiting for retirement isn't woken up again
+		var patch string
+		if _, retiring := deployment.Annotations[retireAfterAnnotation]; retiring {
+			patch = `{"spec":{"replicas":0}}`
+		} else {
+			patch = fmt.Sprintf(`{"metadata":{"annotations":{%q:"%d"}},"spec":{"replicas":0}}`,
+				sleepReplicasAnnotation, replicas)
+		}
+
+		if err := c.patchDeployment(ctx, deployment.Name, patch); err != nil {
+			return err
+		}
+	}
+
+	c.logger.Info("App scaled to zero", zap.String("name", name))
+	return nil
+}
+
+// Wake scales a sleeping app back up, waits for its pods to be ready and
+// routes its Ingress back to it. It returns the ID of the deployment that
+// was woken, or "" when the app wasn't asleep.
+func (c *Client) Wake(ctx context.Context, name string, timeout time.Duration) (string, error) {
+	name = sanitizeK8sName(name)
+
+	deployments, err := c.appDeployments(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	var deploymentID string
+	var woken, serving []string
+	for _, deployment := range deployments {
+		if _, retiring := deployment.Annotations[retireAfterAnnotation]; !retiring {
+			serving = append(serving, deployment.Name)
+		}
+
+		saved, ok := deployment.Annotations[sleepReplicasAnnotation]
+		if !ok {
+			continue
+		}
+
+		replicas, err := strconv.Atoi(saved)
+		if err != nil || replicas < 1 {
+			replicas = 1
+		}
+
+		patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:null}},"spec":{"replicas":%d}}`,
+			sleepReplicasAnnotation, replicas)
+		if err := c.patchDeployment(ctx, deployment.Name, patch); err != nil {
+			return "", err
+		}
+
+		woken = append(woken, deployment.Name)
+		deploymentID = deployment.Spec.Template.Labels["code2cloud/deployment-id"]
+	}
+
+	// Another worker may have started the wake already; traffic only
+	// moves once the pods are ready either way
+	for _, deploymentName := range serving {
+		if err := c.WaitForDeploymentReady(ctx, deploymentName, timeout); err != nil {
+			return "", err
+		}
+	}
+
+	if err := c.routeIngress(ctx, name, name); err != nil {
+		return "", err
+	}
+
+	if len(woken) > 0 {
+		c.logger.Info("App woken up",
+			zap.String("name", name),
+			zap.Strings("deployments", woken),
+		)
+	}
+	return deploymentID, nil
+}
+
+// appDeployments lists an app's Deployments, including blue/green colors.
+func (c *Client) appDeployments(ctx context.Context, name string) ([]appsv1.Deployment, error) {
+	deployments, err := c.clientset.AppsV1().Deployments(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s,app.kubernetes.io/managed-by=code2cloud", name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	return deployments.Items, nil
+}
+
+func (c *Client) patchDeployment(ctx context.Context, name, patch string) error {
+	_, err := c.clientset.AppsV1().Deployments(c.namespace).Patch(
+		ctx, name, k8stypes.MergePatchType, []byte(patch), metav1.PatchOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to scale deployment %s: %w", name, err)
+	}
+	return nil
+}
+
+// routeIngress points every path of an app's Ingress at serviceName.
+func (c *Client) routeIngress(ctx context.Context, name, serviceName string) error {
+	ingressClient := c.clientset.NetworkingV1().Ingresses(c.namespace)
+
+	ingress, err := ingressClient.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get ingress: %w", err)
+	}
+
+	if ingressServiceName(ingress.Spec.Rules, name) == serviceName {
+		return nil
+	}
+
+	hosts := make([]string, 0, len(ingress.Spec.Rules))
+	for _, rule := range ingress.Spec.Rules {
+		hosts = append(hosts, rule.Host)
+	}
+	ingress.Spec.Rules = buildIngressRules(hosts, serviceName)
+
+	if _, err := ingressClient.Update(ctx, ingress, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update ingress: %w", err)
+	}
+	return nil
+}
+
+// appForHost finds the app whose Ingress answers host. It reports whether
+// the app is asleep, i.e. routed to the activator.
+func (c *Client) appForHost(ctx context.Context, host string) (string, bool, error) {
+	host = strings.ToLower(host)
+	if idx := strings.LastIndex(host, ":"); idx >= 0 {
+		host = host[:idx]
+	}
+
+	ingresses, err := c.clientset.NetworkingV1().Ingresses(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/managed-by=code2cloud",
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	for _, ingress := range ingresses.Items {
+		for _, rule := range ingress.Spec.Rules {
+			if strings.EqualFold(rule.Host, host) {
+				asleep := ingressServiceName(ingress.Spec.Rules, ingress.Name) == activatorServiceName
+				return ingress.Name, asleep, nil
+			}
+		}
+	}
+	return "", false, nil
+}
@@ -1,10 +1,25 @@
 package k8s
 
+import (
+	"time"
+
+	"code2cloud/worker/internal/types"
+)
+
 type DeployOptions struct {
 	DeploymentID string
 	ProjectID    string
 	ProjectName  string
 
+	// Environment is types.EnvironmentProduction or types.EnvironmentPreview.
+	// Previews get their own resources, named after Branch.
+	Environment string
+	Branch      string
+
+	// Strategy is types.StrategyRolling (the default),
+	// types.StrategyBlueGreen or types.StrategyCanary
+	Strategy string
+
 	ImageName string
 	Port      int32
 
@@ -13,14 +28,17 @@ type DeployOptions struct {
 	MemoryRequest string
 	MemoryLimit   string
 
+	// Replicas is the minimum when Scaling.MaxReplicas is above it and
+	// an autoscaler manages the count
 	Replicas int32
+	Scaling  types.Scaling
 
 	EnvVars map[string]string
 
 	Domains    []string
 	BaseDomain string
 
-	HealthPath string
+	HealthCheck types.HealthCheck
 
 	Labels map[string]string
 }
@@ -33,11 +51,14 @@ func DefaultDeployOptions() DeployOptions {
 		MemoryRequest: "64Mi",
 		MemoryLimit:   "256Mi",
 		Replicas:      1,
-		HealthPath:    "/health",
 	}
 }
 
 type DeployResult struct {
+	// AppName is the "app" label and container name shared by the app's
+	// pods; DeploymentName differs from it for blue/green colors
+	AppName string
+
 	DeploymentName string
 	ServiceName    string
 	IngressName    string
@@ -45,10 +66,16 @@ type DeployResult struct {
 	URLs []string
 
 	Ready bool
+
+	// Time spent applying resources and waiting for pods to become ready
+	ApplyDuration time.Duration
+	ReadyDuration time.Duration
 }
 
 type CleanupOptions struct {
 	DeploymentID string
 	ProjectName  string
+	Environment  string
+	Branch       string
 	Namespace    string
 }
\ No newline at end of file
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/metrics"
 )
 
 // StreamLogger collects log output and streams it to the API in batches
@@ -177,6 +179,7 @@ func (sl *StreamLogger) flushLocked() {
 // sendToAPI sends logs to the API
 func (sl *StreamLogger) sendToAPI(source Source, messages []string) {
 	if err := sl.sender.SendLogs(sl.deploymentID, source, messages); err != nil {
+		metrics.LogSendFailures.WithLabelValues(string(source)).Inc()
 		sl.zapLogger.Warn("Failed to send logs to API",
 			zap.String("deploymentId", sl.deploymentID),
 			zap.String("source", string(source)),
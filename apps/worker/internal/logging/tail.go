@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+)
+
+// TailBuffer is an io.Writer that keeps only the last N lines written to it.
+// It is used next to a StreamLogger to inspect command output after a failure.
+type TailBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	partial string
+	max     int
+}
+
+// NewTailBuffer creates a buffer holding at most max lines
+func NewTailBuffer(max int) *TailBuffer {
+	if max <= 0 {
+		max = 50
+	}
+	return &TailBuffer{max: max}
+}
+
+// Write implements io.Writer
+func (t *TailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data := t.partial + strings.ReplaceAll(string(p), "\r", "\n")
+	parts := strings.Split(data, "\n")
+
+	// The last element is an unterminated line (or "")
+	t.partial = parts[len(parts)-1]
+	for _, line := range parts[:len(parts)-1] {
+		t.append(line)
+	}
+
+	return len(p), nil
+}
+
+func (t *TailBuffer) append(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	t.lines = append(t.lines, line)
+	if len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+}
+
+// Lines returns a copy of the buffered lines, oldest first
+func (t *TailBuffer) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := make([]string, 0, len(t.lines)+1)
+	lines = append(lines, t.lines...)
+	if strings.TrimSpace(t.partial) != "" {
+		lines = append(lines, t.partial)
+	}
+	return lines
+}
+
+// String returns the buffered lines joined by newlines
+func (t *TailBuffer) String() string {
+	return strings.Join(t.Lines(), "\n")
+}
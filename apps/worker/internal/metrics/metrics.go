@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "code2cloud_worker"
+
+// Job outcomes recorded by JobsProcessed
+const (
+	JobReady    = "ready"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+	JobRetried  = "retried"
+	JobRequeued = "requeued"
+)
+
+// ─────────────────────────────────────────────────────────────
+// Jobs
+// ─────────────────────────────────────────────────────────────
+
+var JobsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "jobs_processed_total",
+	Help:      "Build jobs handled by this worker, by outcome.",
+}, []string{"status"})
+
+var BuildDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "build_duration_seconds",
+	Help:      "Time spent building images with railpack and BuildKit.",
+	Buckets:   []float64{15, 30, 60, 120, 180, 300, 450, 600, 900},
+}, []string{"framework"})
+
+var CloneDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "clone_duration_seconds",
+	Help:      "Time spent cloning repositories.",
+	Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+})
+
+// ─────────────────────────────────────────────────────────────
+// Queues
+// ─────────────────────────────────────────────────────────────
+
+var QueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "queue_depth",
+	Help:      "Jobs waiting in a Redis queue.",
+}, []string{"queue"})
+
+// ─────────────────────────────────────────────────────────────
+// Background Workers
+// ─────────────────────────────────────────────────────────────
+
+var DomainVerifications = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "domain_verifications_total",
+	Help:      "Custom domain verification attempts, by outcome.",
+}, []string{"outcome"})
+
+var CleanupRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "cleanup_runs_total",
+	Help:      "Cleanup passes, by kind (expired, retire, env_secrets, project, preview, logs) and result.",
+}, []string{"kind", "result"})
+
+// ─────────────────────────────────────────────────────────────
+// Logging
+// ─────────────────────────────────────────────────────────────
+
+var LogSendFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "log_send_failures_total",
+	Help:      "Log batches that could not be delivered to the API, by source.",
+}, []string{"source"})
+
+// RegisterActiveStreams exposes the number of runtime log streams. fn is
+// called on every scrape.
+func RegisterActiveStreams(fn func() int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_log_streams",
+		Help:      "Runtime log streams currently attached to pods.",
+	}, func() float64 {
+		return float64(fn())
+	})
+}
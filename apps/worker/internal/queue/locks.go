@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// ─────────────────────────────────────────────────────────────
+// Project Locks
+// ─────────────────────────────────────────────────────────────
+// Jobs that deploy the same Kubernetes resources must not run at once on
+// any worker, including a draining pod and its replacement during a
+// rollout. A lock is a Redis key holding its owner; the heartbeat extends
+// the locks this worker holds, so a crashed worker's locks expire with its
+// heartbeat.
+
+const projectLockTTL = heartbeatTTL
+
+// releaseLockScript deletes a lock only if we still own it.
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extendLockScript refreshes a lock's TTL only if we still own it.
+var extendLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+func projectLockKey(key string) string {
+	return "project-lock:" + key
+}
+
+// TryLockProject takes the lock on key for jobID and reports whether it
+// did. It never waits.
+func (q *Queue) TryLockProject(ctx context.Context, key, jobID string) (bool, error) {
+	owner := q.workerID + "/" + jobID
+
+	ok, err := q.client.SetNX(ctx, projectLockKey(key), owner, projectLockTTL).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to lock project %s: %w", key, err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	q.mu.Lock()
+	q.locks[key] = owner
+	q.mu.Unlock()
+	return true, nil
+}
+
+// UnlockProject releases a lock taken with TryLockProject.
+func (q *Queue) UnlockProject(ctx context.Context, key string) {
+	q.mu.Lock()
+	owner, ok := q.locks[key]
+	delete(q.locks, key)
+	q.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if err := releaseLockScript.Run(ctx, q.client, []string{projectLockKey(key)}, owner).Err(); err != nil {
+		q.logger.Warn("Failed to release project lock, it expires on its own",
+			zap.String("project", key),
+			zap.Error(err),
+		)
+	}
+}
+
+// extendLocks refreshes the TTL of every lock this worker holds.
+func (q *Queue) extendLocks(ctx context.Context) {
+	q.mu.Lock()
+	held := make(map[string]string, len(q.locks))
+	for key, owner := range q.locks {
+		held[key] = owner
+	}
+	q.mu.Unlock()
+
+	for key, owner := range held {
+		n, err := extendLockScript.Run(ctx, q.client, []string{projectLockKey(key)}, owner, projectLockTTL.Milliseconds()).Int()
+		if err != nil {
+			if ctx.Err() == nil {
+				q.logger.Warn("Failed to extend project lock",
+					zap.String("project", key),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		if n == 0 {
+			q.logger.Error("Lost project lock; another job may deploy the same resources",
+				zap.String("project", key),
+			)
+		}
+	}
+}
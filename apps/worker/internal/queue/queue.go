@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -14,15 +16,34 @@ import (
 
 const (
 	ProjectCleanupQueue = "project-cleanup-queue"
+	PreviewCleanupQueue = "preview-cleanup-queue"
+
+	// workersKey is a set of every worker ID that may own a processing list
+	workersKey = "build-workers"
+
+	heartbeatTTL      = 30 * time.Second
+	heartbeatInterval = 10 * time.Second
 )
 
 type Queue struct {
 	client    *redis.Client
-	queueName string        
-	logger    *zap.Logger   
+	queueName string
+	workerID  string
+	logger    *zap.Logger
+
+	// inflight maps a job ID to the raw payload sitting in our processing
+	// list, so it can be acknowledged with LREM once the job is done.
+	mu       sync.Mutex
+	inflight map[string]string
+
+	// locks maps the project locks this worker holds to their owner value
+	locks map[string]string
+
+	// lastPoll is when a blocking pop last returned (unix nanos)
+	lastPoll atomic.Int64
 }
 
-func New(url, queueName string, logger *zap.Logger) (*Queue, error) {
+func New(url, queueName, workerID string, logger *zap.Logger) (*Queue, error) {
 	opts, err := redis.ParseURL(url)
 	if err != nil {
 			return nil, fmt.Errorf("invalid redis url: %w", err)
@@ -43,17 +64,33 @@ func New(url, queueName string, logger *zap.Logger) (*Queue, error) {
 	return &Queue{
 		client:    client,
 		queueName: queueName,
+		workerID:  workerID,
 		logger:    logger,
+		inflight:  make(map[string]string),
+		locks:     make(map[string]string),
 	}, nil
 }
 
+func processingKey(workerID string) string {
+	return "processing:" + workerID
+}
+
+func heartbeatKey(workerID string) string {
+	return "heartbeat:" + workerID
+}
+
 // ─────────────────────────────────────────────────────────────
 // Job Processing
 // ─────────────────────────────────────────────────────────────
 
+// WaitForJob atomically moves the next job into this worker's processing
+// list. The job stays there until CompleteJob, FailJob or RequeueJob, so a
+// crash mid-build never loses it.
 func (q *Queue) WaitForJob(ctx context.Context) (*types.BuildJob, string, error) {
 	q.logger.Debug("Waiting for job...", zap.String("key", q.queueName))
 
+	processing := processingKey(q.workerID)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -62,8 +99,9 @@ func (q *Queue) WaitForJob(ctx context.Context) (*types.BuildJob, string, error)
 			// Continue processing
 		}
 
-		result, err := q.client.BRPop(ctx, 5*time.Second, q.queueName).Result()
-		
+		rawJSON, err := q.client.BLMove(ctx, q.queueName, processing, "RIGHT", "LEFT", 5*time.Second).Result()
+		q.lastPoll.Store(time.Now().UnixNano())
+
 		if err == redis.Nil {
 			continue
 		}
@@ -74,19 +112,23 @@ func (q *Queue) WaitForJob(ctx context.Context) (*types.BuildJob, string, error)
 			return nil, "", fmt.Errorf("failed to pop job: %w", err)
 		}
 
-		rawJSON := result[1]
-
 		var job types.BuildJob
 		if err := json.Unmarshal([]byte(rawJSON), &job); err != nil {
 			q.logger.Error("Failed to parse job data",
 				zap.String("raw", rawJSON),
 				zap.Error(err),
 			)
+			// Drop it so it isn't requeued forever
+			q.client.LRem(ctx, processing, 1, rawJSON)
 			continue
 		}
 
 		jobID := job.DeploymentID
 
+		q.mu.Lock()
+		q.inflight[jobID] = rawJSON
+		q.mu.Unlock()
+
 		q.logger.Info("Got job",
 			zap.String("jobId", jobID),
 			zap.String("project", job.ProjectName),
@@ -129,30 +171,217 @@ func (q *Queue) PopProjectCleanup(ctx context.Context) (*types.ProjectCleanupJob
 	return &job, nil
 }
 
+// PopPreviewCleanup returns the next closed pull request whose preview
+// environment should be removed, or nil if there is none.
+func (q *Queue) PopPreviewCleanup(ctx context.Context) (*types.PreviewCleanupJob, error) {
+	result, err := q.client.RPop(ctx, PreviewCleanupQueue).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		q.logger.Warn("Failed to pop preview cleanup job", zap.Error(err))
+		return nil, err
+	}
+
+	var job types.PreviewCleanupJob
+	if err := json.Unmarshal([]byte(result), &job); err != nil {
+		q.logger.Error("Failed to parse preview cleanup job",
+			zap.String("raw", result),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse preview cleanup job: %w", err)
+	}
+
+	q.logger.Info("Got preview cleanup job",
+		zap.String("project_id", job.ProjectID),
+		zap.String("project_name", job.ProjectName),
+		zap.String("branch", job.Branch),
+	)
+
+	return &job, nil
+}
+
 // ─────────────────────────────────────────────────────────────
 // Job Completion
 // ─────────────────────────────────────────────────────────────
 
 func (q *Queue) CompleteJob(ctx context.Context, jobID string) error {
+	if err := q.ack(ctx, jobID); err != nil {
+		return err
+	}
 	q.logger.Info("Job completed", zap.String("jobId", jobID))
 	return nil
 }
 
 func (q *Queue) FailJob(ctx context.Context, jobID, reason string) error {
-	q.logger.Error("Job failed", 
+	if err := q.ack(ctx, jobID); err != nil {
+		return err
+	}
+	q.logger.Error("Job failed",
 		zap.String("jobId", jobID),
 		zap.String("reason", reason),
 	)
 	return nil
 }
 
+// RequeueJob hands an in-flight job back to the queue, e.g. when shutdown
+// interrupts it. It is picked up before any other waiting job.
+func (q *Queue) RequeueJob(ctx context.Context, jobID string) error {
+	raw, ok := q.takeInflight(jobID)
+	if !ok {
+		return fmt.Errorf("job %s is not in flight", jobID)
+	}
+
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, processingKey(q.workerID), 1, raw)
+		pipe.RPush(ctx, q.queueName, raw)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to requeue job %s: %w", jobID, err)
+	}
+
+	q.logger.Info("Job requeued", zap.String("jobId", jobID))
+	return nil
+}
+
+// ack removes a finished job from this worker's processing list.
+func (q *Queue) ack(ctx context.Context, jobID string) error {
+	raw, ok := q.takeInflight(jobID)
+	if !ok {
+		return nil
+	}
+
+	if err := q.client.LRem(ctx, processingKey(q.workerID), 1, raw).Err(); err != nil {
+		q.logger.Warn("Failed to acknowledge job",
+			zap.String("jobId", jobID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to acknowledge job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+func (q *Queue) takeInflight(jobID string) (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	raw, ok := q.inflight[jobID]
+	delete(q.inflight, jobID)
+	return raw, ok
+}
+
+// ─────────────────────────────────────────────────────────────
+// Worker Liveness
+// ─────────────────────────────────────────────────────────────
+
+// StartHeartbeat registers this worker and keeps its heartbeat key and
+// project locks alive until ctx is cancelled. Reapers treat a missing key
+// as a dead worker.
+func (q *Queue) StartHeartbeat(ctx context.Context) {
+	q.beat(ctx)
+
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				q.beat(ctx)
+			}
+		}
+	}()
+}
+
+func (q *Queue) beat(ctx context.Context) {
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, heartbeatKey(q.workerID), time.Now().UTC().Format(time.RFC3339), heartbeatTTL)
+		pipe.SAdd(ctx, workersKey, q.workerID)
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		q.logger.Warn("Failed to send worker heartbeat",
+			zap.String("worker_id", q.workerID),
+			zap.Error(err),
+		)
+	}
+
+	q.extendLocks(ctx)
+}
+
+// Deregister removes this worker's heartbeat on clean shutdown. Jobs still
+// in the processing list are left for a reaper to requeue.
+func (q *Queue) Deregister(ctx context.Context) {
+	pending, err := q.client.LLen(ctx, processingKey(q.workerID)).Result()
+	if err != nil {
+		q.logger.Warn("Failed to check processing list on shutdown", zap.Error(err))
+		return
+	}
+
+	pipe := q.client.TxPipeline()
+	pipe.Del(ctx, heartbeatKey(q.workerID))
+	if pending == 0 {
+		pipe.SRem(ctx, workersKey, q.workerID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		q.logger.Warn("Failed to deregister worker", zap.Error(err))
+		return
+	}
+
+	q.logger.Info("Worker deregistered",
+		zap.String("worker_id", q.workerID),
+		zap.Int64("pending_jobs", pending),
+	)
+}
+
+// isAlive reports whether a worker's heartbeat key still exists.
+func (q *Queue) isAlive(ctx context.Context, workerID string) (bool, error) {
+	n, err := q.client.Exists(ctx, heartbeatKey(workerID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// requeueProcessing moves every job in a worker's processing list back
+// onto the queue and returns the jobs that were moved.
+func (q *Queue) requeueProcessing(ctx context.Context, workerID string) ([]*types.BuildJob, error) {
+	var jobs []*types.BuildJob
+
+	for {
+		raw, err := q.client.LMove(ctx, processingKey(workerID), q.queueName, "RIGHT", "RIGHT").Result()
+		if err == redis.Nil {
+			return jobs, nil
+		}
+		if err != nil {
+			return jobs, fmt.Errorf("failed to requeue jobs for %s: %w", workerID, err)
+		}
+
+		var job types.BuildJob
+		if err := json.Unmarshal([]byte(raw), &job); err != nil {
+			q.logger.Warn("Requeued unparseable job",
+				zap.String("worker_id", workerID),
+				zap.Error(err),
+			)
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+}
+
 // ─────────────────────────────────────────────────────────────
 // Cancellation Signals
 // ─────────────────────────────────────────────────────────────
 
+func cancelKey(deploymentID string) string {
+	return "cancel:" + deploymentID
+}
+
 func (q *Queue) IsCancelled(ctx context.Context, deploymentID string) bool {
-	key := "cancel:" + deploymentID
-	val, err := q.client.Get(ctx, key).Result()
+	val, err := q.client.Get(ctx, cancelKey(deploymentID)).Result()
 	if err != nil {
 		return false
 	}
@@ -160,10 +389,98 @@ func (q *Queue) IsCancelled(ctx context.Context, deploymentID string) bool {
 }
 
 func (q *Queue) ClearCancelSignal(ctx context.Context, deploymentID string) {
-	key := "cancel:" + deploymentID
-	q.client.Del(ctx, key)
+	q.client.Del(ctx, cancelKey(deploymentID))
+}
+
+// WatchCancel calls onCancel as soon as the API publishes a cancel signal on
+// the deployment's channel, or right away if the cancel key is already set.
+// onCancel runs at most once. The returned func stops watching and must be
+// called when the job finishes.
+func (q *Queue) WatchCancel(ctx context.Context, deploymentID string, onCancel func()) func() {
+	sub := q.client.Subscribe(ctx, cancelKey(deploymentID))
+
+	// Wait for the subscription to be live so a signal published between
+	// here and the key check below can't slip through.
+	if _, err := sub.Receive(ctx); err != nil {
+		q.logger.Warn("Failed to subscribe to cancel signals, relying on checkpoints",
+			zap.String("deployment", deploymentID),
+			zap.Error(err),
+		)
+	}
+
+	var once sync.Once
+	fire := func() { once.Do(onCancel) }
+
+	if q.IsCancelled(ctx, deploymentID) {
+		fire()
+	}
+
+	done := make(chan struct{})
+	messages := sub.Channel()
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case _, ok := <-messages:
+				if !ok {
+					return
+				}
+				fire()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		sub.Close()
+	}
+}
+
+// LastPoll returns when WaitForJob last heard back from Redis, or the zero
+// time if it has never polled.
+func (q *Queue) LastPoll() time.Time {
+	nanos := q.lastPoll.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// Ping verifies the Redis connection.
+func (q *Queue) Ping(ctx context.Context) error {
+	return q.client.Ping(ctx).Err()
+}
+
+// ─────────────────────────────────────────────────────────────
+// Queue Depth
+// ─────────────────────────────────────────────────────────────
+
+// Depths returns the length of the build queue, its delayed-retry and
+// dead-letter sets, and the project and preview cleanup queues, keyed by
+// Redis key.
+func (q *Queue) Depths(ctx context.Context) (map[string]int64, error) {
+	pipe := q.client.Pipeline()
+	build := pipe.LLen(ctx, q.queueName)
+	delayed := pipe.ZCard(ctx, q.delayedKey())
+	dead := pipe.LLen(ctx, q.deadLetterKey())
+	cleanup := pipe.LLen(ctx, ProjectCleanupQueue)
+	previewCleanup := pipe.LLen(ctx, PreviewCleanupQueue)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to read queue depths: %w", err)
+	}
+
+	return map[string]int64{
+		q.queueName:          build.Val(),
+		q.delayedKey():       delayed.Val(),
+		q.deadLetterKey():    dead.Val(),
+		ProjectCleanupQueue: cleanup.Val(),
+		PreviewCleanupQueue: previewCleanup.Val(),
+	}, nil
 }
 
 func (q *Queue) Close() error {
 	return q.client.Close()
-}
\ No newline at end of file
+}
@@ -0,0 +1,143 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/types"
+)
+
+// Reaper requeues jobs stranded in the processing list of a worker whose
+// heartbeat has expired (crashed pod, OOM kill, node loss).
+type Reaper struct {
+	queue  *Queue
+	logger *zap.Logger
+
+	onRequeue func(ctx context.Context, job *types.BuildJob, workerID string)
+
+	checkInterval time.Duration
+	wg            sync.WaitGroup
+}
+
+type ReaperConfig struct {
+	Queue     *Queue
+	Logger    *zap.Logger
+	OnRequeue func(ctx context.Context, job *types.BuildJob, workerID string)
+
+	CheckInterval time.Duration
+}
+
+func NewReaper(config ReaperConfig) *Reaper {
+	interval := config.CheckInterval
+	if interval == 0 {
+		interval = 15 * time.Second
+	}
+
+	return &Reaper{
+		queue:         config.Queue,
+		logger:        config.Logger,
+		onRequeue:     config.OnRequeue,
+		checkInterval: interval,
+	}
+}
+
+// Start reclaims jobs left behind by a previous run of this worker ID, then
+// keeps sweeping for dead workers in the background.
+func (r *Reaper) Start(ctx context.Context) {
+	r.requeueWorker(ctx, r.queue.workerID)
+
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		r.logger.Info("Queue reaper started",
+			zap.Duration("check_interval", r.checkInterval),
+		)
+
+		ticker := time.NewTicker(r.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				r.logger.Info("Queue reaper stopped")
+				return
+			case <-ticker.C:
+				r.reap(ctx)
+			}
+		}
+	}()
+}
+
+func (r *Reaper) Stop() {
+	r.wg.Wait()
+}
+
+func (r *Reaper) reap(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	workers, err := r.queue.client.SMembers(ctx, workersKey).Result()
+	if err != nil {
+		r.logger.Warn("Failed to list workers", zap.Error(err))
+		return
+	}
+
+	for _, workerID := range workers {
+		if workerID == r.queue.workerID {
+			continue
+		}
+
+		alive, err := r.queue.isAlive(ctx, workerID)
+		if err != nil {
+			r.logger.Warn("Failed to check worker heartbeat",
+				zap.String("worker_id", workerID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if alive {
+			continue
+		}
+
+		r.logger.Warn("Worker heartbeat expired, reclaiming its jobs",
+			zap.String("worker_id", workerID),
+		)
+
+		if r.requeueWorker(ctx, workerID) {
+			r.queue.client.SRem(ctx, workersKey, workerID)
+		}
+	}
+}
+
+// requeueWorker moves a worker's processing list back onto the queue and
+// reports whether the list was fully drained.
+func (r *Reaper) requeueWorker(ctx context.Context, workerID string) bool {
+	jobs, err := r.queue.requeueProcessing(ctx, workerID)
+
+	for _, job := range jobs {
+		r.logger.Info("Requeued stranded job",
+			zap.String("worker_id", workerID),
+			zap.String("deployment", job.DeploymentID),
+			zap.String("project", job.ProjectName),
+		)
+		if r.onRequeue != nil {
+			r.onRequeue(ctx, job, workerID)
+		}
+	}
+
+	if err != nil {
+		r.logger.Warn("Failed to reclaim jobs",
+			zap.String("worker_id", workerID),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	return true
+}
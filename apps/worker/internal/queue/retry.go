@@ -0,0 +1,245 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/types"
+)
+
+const (
+	// deadLetterLimit caps the dead-letter list so it can't grow unbounded
+	deadLetterLimit = 1000
+
+	promoteInterval = 2 * time.Second
+)
+
+// promoteScript atomically moves every due job from the delayed set onto the
+// queue, so two workers promoting at once never duplicate a job.
+var promoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 50)
+for _, job in ipairs(due) do
+	redis.call('ZREM', KEYS[1], job)
+	redis.call('RPUSH', KEYS[2], job)
+end
+return #due
+`)
+
+func (q *Queue) delayedKey() string {
+	return q.queueName + ":delayed"
+}
+
+func (q *Queue) deadLetterKey() string {
+	return q.queueName + ":dead"
+}
+
+// ─────────────────────────────────────────────────────────────
+// Retries
+// ─────────────────────────────────────────────────────────────
+
+// RetryJob acknowledges the in-flight job and schedules the updated payload
+// (with its new attempt count) to re-enter the queue after delay.
+func (q *Queue) RetryJob(ctx context.Context, job *types.BuildJob, jobID string, delay time.Duration) error {
+	if err := q.schedule(ctx, job, jobID, delay); err != nil {
+		return fmt.Errorf("failed to schedule retry for %s: %w", jobID, err)
+	}
+
+	q.logger.Info("Job scheduled for retry",
+		zap.String("jobId", jobID),
+		zap.Int("attempt", job.Attempt),
+		zap.Duration("delay", delay),
+	)
+	return nil
+}
+
+// DeferJob acknowledges the in-flight job and puts it back on the queue
+// after delay without counting an attempt, e.g. while another job of the
+// same project holds its lock.
+func (q *Queue) DeferJob(ctx context.Context, job *types.BuildJob, jobID string, delay time.Duration) error {
+	if err := q.schedule(ctx, job, jobID, delay); err != nil {
+		return fmt.Errorf("failed to defer job %s: %w", jobID, err)
+	}
+
+	q.logger.Info("Job deferred",
+		zap.String("jobId", jobID),
+		zap.Duration("delay", delay),
+	)
+	return nil
+}
+
+// schedule moves the in-flight job to the delayed set, due after delay.
+func (q *Queue) schedule(ctx context.Context, job *types.BuildJob, jobID string, delay time.Duration) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	raw, _ := q.takeInflight(jobID)
+	dueAt := time.Now().Add(delay)
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if raw != "" {
+			pipe.LRem(ctx, processingKey(q.workerID), 1, raw)
+		}
+		pipe.ZAdd(ctx, q.delayedKey(), redis.Z{
+			Score:  float64(dueAt.Unix()),
+			Member: string(payload),
+		})
+		return nil
+	})
+	return err
+}
+
+// StartRetryPromoter moves due retries back onto the queue until ctx is
+// cancelled.
+func (q *Queue) StartRetryPromoter(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(promoteInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				q.promoteDue(ctx)
+			}
+		}
+	}()
+}
+
+func (q *Queue) promoteDue(ctx context.Context) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	n, err := promoteScript.Run(ctx, q.client, []string{q.delayedKey(), q.queueName}, now).Int()
+	if err != nil {
+		if ctx.Err() == nil {
+			q.logger.Warn("Failed to promote delayed jobs", zap.Error(err))
+		}
+		return
+	}
+
+	if n > 0 {
+		q.logger.Info("Promoted delayed jobs", zap.Int("count", n))
+	}
+}
+
+// ─────────────────────────────────────────────────────────────
+// Dead-Letter Queue
+// ─────────────────────────────────────────────────────────────
+
+// DeadLetterJob acknowledges the in-flight job and records it on the
+// dead-letter list together with its attempt count and last error.
+func (q *Queue) DeadLetterJob(ctx context.Context, job *types.BuildJob, jobID, reason string, transient bool) error {
+	letter := types.DeadLetter{
+		Job:       *job,
+		Attempts:  job.Attempt + 1,
+		LastError: reason,
+		Transient: transient,
+		WorkerID:  q.workerID,
+		FailedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	payload, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	raw, _ := q.takeInflight(jobID)
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if raw != "" {
+			pipe.LRem(ctx, processingKey(q.workerID), 1, raw)
+		}
+		pipe.LPush(ctx, q.deadLetterKey(), string(payload))
+		pipe.LTrim(ctx, q.deadLetterKey(), 0, deadLetterLimit-1)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter job %s: %w", jobID, err)
+	}
+
+	q.logger.Error("Job moved to dead-letter queue",
+		zap.String("jobId", jobID),
+		zap.Int("attempts", letter.Attempts),
+		zap.Bool("transient", transient),
+		zap.String("reason", reason),
+	)
+	return nil
+}
+
+// DeadLetters returns up to limit dead letters, newest first.
+func (q *Queue) DeadLetters(ctx context.Context, limit int64) ([]types.DeadLetter, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	raws, err := q.client.LRange(ctx, q.deadLetterKey(), 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letters: %w", err)
+	}
+
+	letters := make([]types.DeadLetter, 0, len(raws))
+	for _, raw := range raws {
+		var letter types.DeadLetter
+		if err := json.Unmarshal([]byte(raw), &letter); err != nil {
+			q.logger.Warn("Skipping unparseable dead letter", zap.Error(err))
+			continue
+		}
+		letters = append(letters, letter)
+	}
+
+	return letters, nil
+}
+
+// ReplayDeadLetter removes a deployment's dead letter and puts its job back
+// on the queue with a fresh attempt count.
+func (q *Queue) ReplayDeadLetter(ctx context.Context, deploymentID string) (*types.BuildJob, error) {
+	raws, err := q.client.LRange(ctx, q.deadLetterKey(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letters: %w", err)
+	}
+
+	for _, raw := range raws {
+		var letter types.DeadLetter
+		if err := json.Unmarshal([]byte(raw), &letter); err != nil {
+			continue
+		}
+		if letter.Job.DeploymentID != deploymentID {
+			continue
+		}
+
+		job := letter.Job
+		job.Attempt = 0
+		job.LastError = ""
+		job.QueuedAt = time.Now().UTC().Format(time.RFC3339Nano)
+
+		payload, err := json.Marshal(job)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal job: %w", err)
+		}
+
+		_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.LRem(ctx, q.deadLetterKey(), 1, raw)
+			pipe.RPush(ctx, q.queueName, string(payload))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay dead letter: %w", err)
+		}
+
+		q.logger.Info("Replayed dead letter",
+			zap.String("deployment", deploymentID),
+			zap.Int("previous_attempts", letter.Attempts),
+		)
+		return &job, nil
+	}
+
+	return nil, fmt.Errorf("no dead letter found for deployment %s", deploymentID)
+}
@@ -0,0 +1,49 @@
+package types
+
+import "errors"
+
+// TransientError marks a failure that is likely to succeed if the job is
+// retried (network blips, registry 5xx, BuildKit restarts). Anything not
+// wrapped in it is treated as permanent.
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// Transient wraps err as retryable. A nil err stays nil.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err}
+}
+
+// IsTransient reports whether err (or anything it wraps) is retryable.
+func IsTransient(err error) bool {
+	var t *TransientError
+	return errors.As(err, &t)
+}
+
+// CommandError describes a failed child process (git, railpack, buildctl)
+// so failure reports can include its exit code and last lines of output.
+type CommandError struct {
+	Command  string
+	ExitCode int
+	Output   []string
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
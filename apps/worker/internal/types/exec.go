@@ -0,0 +1,18 @@
+package types
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// KillProcessGroup makes cancelling cmd's context kill its whole process
+// group, so helpers the tool spawned (git-remote-https, buildctl sessions)
+// stop with it instead of running on after a deployment is cancelled.
+func KillProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = 10 * time.Second
+}
@@ -6,9 +6,104 @@ type BuildConfig struct {
 	RunCommand     string `json:"runCommand,omitempty"`
 	OutputDir      string `json:"outputDir,omitempty"`
 	Framework      string `json:"framework"`
+	Dockerfile     string `json:"dockerfile,omitempty"`
+	DockerTarget   string `json:"dockerTarget,omitempty"`
+}
+
+// ─────────────────────────────────────────────────────────────
+// Job Types
+// ─────────────────────────────────────────────────────────────
+
+const (
+	// JobTypeBuild clones, builds and deploys a commit (the default)
+	JobTypeBuild = "build"
+
+	// JobTypeRollback redeploys an already-built image without cloning
+	JobTypeRollback = "rollback"
+)
+
+const (
+	// ModeFull clones, builds and deploys (the default)
+	ModeFull = "full"
+
+	// ModeBuildOnly clones, builds and pushes the image without deploying
+	ModeBuildOnly = "build-only"
+
+	// ModeDeployOnly deploys ContainerImage without touching git or the builder
+	ModeDeployOnly = "deploy-only"
+)
+
+const (
+	// EnvironmentProduction is the project's live deployment (the default)
+	EnvironmentProduction = "PRODUCTION"
+
+	// EnvironmentPreview is a per-branch deployment for a pull request,
+	// isolated from production's Kubernetes resources
+	EnvironmentPreview = "PREVIEW"
+)
+
+const (
+	// StrategyRolling updates the app's Deployment in place (the default)
+	StrategyRolling = "ROLLING"
+
+	// StrategyBlueGreen starts the new version next to the old one and
+	// switches traffic once it is verified healthy
+	StrategyBlueGreen = "BLUE_GREEN"
+
+	// StrategyCanary shifts traffic to the new version in weighted steps
+	// through Istio and backs out if it regresses
+	StrategyCanary = "CANARY"
+)
+
+// HealthCheck configures an app's probes. Without a Path the kubelet only
+// checks that the port accepts TCP connections. Zero values keep the
+// defaults.
+type HealthCheck struct {
+	Path string `json:"path,omitempty"`
+
+	// Status Path must answer with; 0 accepts any 2xx or 3xx
+	ExpectedStatus int `json:"expectedStatus,omitempty"`
+
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int32 `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      int32 `json:"timeoutSeconds,omitempty"`
+	FailureThreshold    int32 `json:"failureThreshold,omitempty"`
+
+	// Lets slow apps take this long to start before liveness checks can
+	// restart them; 0 disables the startup probe
+	StartupTimeoutSeconds int32 `json:"startupTimeoutSeconds,omitempty"`
+}
+
+// Scaling bounds an app's replicas. When MaxReplicas exceeds MinReplicas a
+// HorizontalPodAutoscaler moves between them to hold the utilization
+// targets (percent of the container's requests).
+type Scaling struct {
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+
+	TargetCPUPercent    int32 `json:"targetCpuPercent,omitempty"`
+	TargetMemoryPercent int32 `json:"targetMemoryPercent,omitempty"`
 }
 
 type BuildJob struct {
+	// Job type; empty means JobTypeBuild
+	Type string `json:"type,omitempty"`
+
+	// Pipeline mode; empty means ModeFull
+	Mode string `json:"mode,omitempty"`
+
+	// Target environment; empty means EnvironmentProduction
+	Environment string `json:"environment,omitempty"`
+
+	// Deploy strategy; empty means StrategyRolling
+	Strategy string `json:"strategy,omitempty"`
+
+	// Probe configuration; nil keeps the TCP defaults
+	HealthCheck *HealthCheck `json:"healthCheck,omitempty"`
+
+	// Replica bounds; nil runs a single replica
+	Scaling *Scaling `json:"scaling,omitempty"`
+
 	DeploymentID   string `json:"deploymentId"`
 	ProjectID      string `json:"projectId"`
 	ProjectName    string `json:"projectName"`
@@ -24,9 +119,79 @@ type BuildJob struct {
 	// []string = array of strings
 	Domains []string `json:"domains"`
 
-	// map[string]string = { [key: string]: string }
+	// Runtime env vars, set on the running app only
 	EnvVars map[string]string `json:"envVars"`
+
+	// Build env vars, passed to the build as build args or build secrets
+	// and never set on the running app
+	BuildEnvVars map[string]string `json:"buildEnvVars,omitempty"`
+
+	// Build env var keys a Dockerfile build gets as secrets instead of
+	// build args, per the project's settings
+	BuildSecrets []string `json:"buildSecrets,omitempty"`
+
 	PreviousDeploymentID string `json:"previousDeploymentId,omitempty"`
+
+	// Deploy-only and rollback jobs: the image to deploy, its digest when
+	// the build reported one, and the deployment that built it
+	ContainerImage     string `json:"containerImage,omitempty"`
+	ImageDigest        string `json:"imageDigest,omitempty"`
+	SourceDeploymentID string `json:"sourceDeploymentId,omitempty"`
+
+	// RFC3339 time the API enqueued the job, or a retry or replay re-entered
+	// the queue (used for queue wait metrics)
+	QueuedAt string `json:"queuedAt,omitempty"`
+
+	// Retry bookkeeping, set by the worker when a transient failure requeues the job
+	Attempt   int    `json:"attempt,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// JobType returns the job's type, defaulting to JobTypeBuild.
+func (j *BuildJob) JobType() string {
+	if j.Type == "" {
+		return JobTypeBuild
+	}
+	return j.Type
+}
+
+func (j *BuildJob) IsRollback() bool {
+	return j.Type == JobTypeRollback
+}
+
+// JobMode returns the job's pipeline mode. Rollbacks are always deploy-only.
+func (j *BuildJob) JobMode() string {
+	if j.IsRollback() {
+		return ModeDeployOnly
+	}
+	if j.Mode == "" {
+		return ModeFull
+	}
+	return j.Mode
+}
+
+// JobEnvironment returns the job's target environment, defaulting to
+// EnvironmentProduction.
+func (j *BuildJob) JobEnvironment() string {
+	if j.Environment == "" {
+		return EnvironmentProduction
+	}
+	return j.Environment
+}
+
+func (j *BuildJob) IsPreview() bool {
+	return j.Environment == EnvironmentPreview
+}
+
+// DeadLetter is a job that failed permanently or ran out of retries.
+// It is kept in Redis so operators can inspect and replay it.
+type DeadLetter struct {
+	Job       BuildJob `json:"job"`
+	Attempts  int      `json:"attempts"`
+	LastError string   `json:"lastError"`
+	Transient bool     `json:"transient"`
+	WorkerID  string   `json:"workerId"`
+	FailedAt  string   `json:"failedAt"`
 }
 
 // ─────────────────────────────────────────────────────────────
@@ -39,10 +204,12 @@ const (
 	StatusBuilding  DeploymentStatus = "BUILDING"
 	StatusDeploying DeploymentStatus = "DEPLOYING"
 	StatusReady     DeploymentStatus = "READY"
+	StatusBuilt     DeploymentStatus = "BUILT"
 	StatusFailed    DeploymentStatus = "FAILED"
 	StatusCanceled  DeploymentStatus = "CANCELED"
 	StatusExpired   DeploymentStatus = "EXPIRED"
 	StatusSuperseded DeploymentStatus = "SUPERSEDED"
+	StatusSleeping  DeploymentStatus = "SLEEPING"
 )
 
 // ─────────────────────────────────────────────────────────────
@@ -62,14 +229,29 @@ type LogEntry struct {
 	Message      string
 }
 
+// ReplicaCount is a deployment's current and desired replicas, reported to
+// the API.
+type ReplicaCount struct {
+	DeploymentID    string `json:"deploymentId"`
+	CurrentReplicas int32  `json:"currentReplicas"`
+	DesiredReplicas int32  `json:"desiredReplicas"`
+}
+
 // ExpiredDeployment represents a deployment that has exceeded its TTL
+// without a request waking it
 type ExpiredDeployment struct {
 	ID             string `json:"id"`
 	ProjectID      string `json:"projectId"`
 	ProjectName    string `json:"projectName"`
 	ContainerImage string `json:"containerImage"`
+	Environment    string `json:"environment"`
+	Branch         string `json:"branch"`
 	TTLMinutes     int    `json:"ttlMinutes"`
 	ExpiredAt      string `json:"expiredAt"`
+
+	// Scale to zero and keep serving through the activator instead of
+	// deleting everything
+	ScaleToZero bool `json:"scaleToZero"`
 }
 
 type ProjectCleanupJob struct {
@@ -77,3 +259,12 @@ type ProjectCleanupJob struct {
 	ProjectName         string   `json:"projectName"`
 	ActiveDeploymentIDs []string `json:"activeDeploymentIds"`
 }
+
+// PreviewCleanupJob removes a branch's preview environment once its pull
+// request is closed or the branch is deleted.
+type PreviewCleanupJob struct {
+	ProjectID     string   `json:"projectId"`
+	ProjectName   string   `json:"projectName"`
+	Branch        string   `json:"branch"`
+	DeploymentIDs []string `json:"deploymentIds"`
+}
@@ -0,0 +1,26 @@
+package types
+
+// RegistryType is the kind of package registry a credential is for
+type RegistryType string
+
+const (
+	RegistryNPM  RegistryType = "NPM"
+	RegistryPyPI RegistryType = "PYPI"
+	RegistryGo   RegistryType = "GO"
+)
+
+// RegistryCredential lets builds install from a private package registry.
+// Token is the decrypted secret; it must never be logged or written into
+// the build context.
+type RegistryCredential struct {
+	Type RegistryType `json:"type"`
+
+	// Registry URL (npm), index URL (PyPI) or git host / module proxy (Go)
+	URL string `json:"url"`
+
+	// npm package scope ("@acme") or Go module pattern ("github.com/acme/*")
+	Scope string `json:"scope,omitempty"`
+
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token"`
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/api"
+	"code2cloud/worker/internal/builder"
+	"code2cloud/worker/internal/types"
+)
+
+// processDeployOnly deploys an image that was already built, for
+// deploy-only jobs and rollbacks. It skips git and the builder entirely and
+// reuses the regular deploy and release path.
+func (w *Worker) processDeployOnly(ctx context.Context, job *types.BuildJob, timings *api.DeploymentTimings) error {
+	startTime := time.Now()
+
+	buildLog := w.logFactory.CreateBuildLogger(job.DeploymentID)
+	defer buildLog.Close()
+
+	w.logger.Info("Starting deploy-only job",
+		zap.String("deployment", job.DeploymentID),
+		zap.String("project", job.ProjectName),
+		zap.String("type", job.JobType()),
+		zap.String("image", job.ContainerImage),
+		zap.String("source", job.SourceDeploymentID),
+	)
+
+	if job.ContainerImage == "" {
+		return phaseError(api.PhaseSetup, "IMAGE_MISSING", errors.New("deploy-only job has no container image"))
+	}
+
+	if err := w.checkCancelled(ctx, job.DeploymentID, api.PhaseSetup); err != nil {
+		return err
+	}
+
+	if err := w.api.UpdateDeploymentStatus(ctx, job.DeploymentID, types.StatusDeploying); err != nil {
+		return phaseError(api.PhaseSetup, "STATUS_UPDATE_FAILED", fmt.Errorf("failed to update status to DEPLOYING: %w", err))
+	}
+
+	title := fmt.Sprintf("  🚢 Code2Cloud Deploy - %s", job.ProjectName)
+	if job.IsRollback() {
+		title = fmt.Sprintf("  ⏪ Code2Cloud Rollback - %s", job.ProjectName)
+	}
+
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log(title)
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log(fmt.Sprintf("  Image:     %s", job.ContainerImage))
+	if job.ImageDigest != "" {
+		buildLog.Log(fmt.Sprintf("  Digest:    %s", job.ImageDigest))
+	}
+	if job.SourceDeploymentID != "" {
+		buildLog.Log(fmt.Sprintf("  From:      deployment %s", job.SourceDeploymentID))
+	}
+	if len(job.CommitHash) >= 8 {
+		buildLog.Log(fmt.Sprintf("  Commit:    %s", job.CommitHash[:8]))
+	}
+	buildLog.Log(fmt.Sprintf("  Domains:   %v", job.Domains))
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log("")
+
+	settings, err := w.api.GetProjectSettings(ctx, job.ProjectID)
+	if err != nil {
+		w.logger.Warn("Failed to get project settings, using defaults", zap.Error(err))
+	}
+
+	buildLog.Log("🚢 Deploy to Kubernetes (no rebuild)")
+	buildLog.Log("─────────────────────────────────────────────────────────────")
+
+	deployOpts := w.deployOptions(job, builder.PinnedImage(job.ContainerImage, job.ImageDigest), settings)
+	buildLog.Log(fmt.Sprintf("Container port: %d", deployOpts.Port))
+
+	w.logStreamer.WatchEvents(context.WithoutCancel(ctx), job.DeploymentID, deployOpts.ResourceName())
+
+	deployResult, err := w.k8s.Deploy(ctx, deployOpts)
+	if err != nil {
+		return deployError(err)
+	}
+
+	timings.ApplyMs = millis(deployResult.ApplyDuration)
+	timings.ReadinessMs = millis(deployResult.ReadyDuration)
+
+	buildLog.Log("")
+
+	return w.release(ctx, job, deployResult, startTime, timings, buildLog)
+}
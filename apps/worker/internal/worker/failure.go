@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code2cloud/worker/internal/api"
+	"code2cloud/worker/internal/k8s"
+	"code2cloud/worker/internal/types"
+)
+
+// jobError tags a processJob failure with the pipeline phase it happened in
+// and a stable code the dashboard can key on.
+type jobError struct {
+	phase api.FailurePhase
+	code  string
+	err   error
+}
+
+func (e *jobError) Error() string {
+	return e.err.Error()
+}
+
+func (e *jobError) Unwrap() error {
+	return e.err
+}
+
+func phaseError(phase api.FailurePhase, code string, err error) error {
+	return &jobError{phase: phase, code: code, err: err}
+}
+
+// buildFailureCode distinguishes plan generation, timeouts and image builds.
+func buildFailureCode(err error) string {
+	var cmdErr *types.CommandError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "BUILD_TIMEOUT"
+	case errors.As(err, &cmdErr) && cmdErr.Command == "railpack":
+		return "BUILD_PLAN_FAILED"
+	default:
+		return "BUILD_FAILED"
+	}
+}
+
+// deployError tags a Deploy failure. Pods that never became ready fail the
+// health phase with a code per cause; anything else is a deploy failure.
+func deployError(err error) error {
+	var notReady *k8s.ReadinessError
+	if !errors.As(err, &notReady) {
+		return phaseError(api.PhaseDeploy, "DEPLOY_FAILED", fmt.Errorf("kubernetes deployment failed: %w", err))
+	}
+
+	var code string
+	switch notReady.Reason {
+	case "CrashLoopBackOff":
+		code = "CRASH_LOOP"
+	case "ImagePullBackOff", "InvalidImageName":
+		code = "IMAGE_PULL_FAILED"
+	case k8s.ReasonOOMKilled:
+		code = "OOM_KILLED"
+	case k8s.ReasonProbeFailed:
+		code = "PROBE_FAILED"
+	case k8s.ReasonUnschedulable:
+		code = "UNSCHEDULABLE"
+	case k8s.ReasonTimeout:
+		code = "READY_TIMEOUT"
+	default:
+		code = "CONTAINER_FAILED"
+	}
+
+	return phaseError(api.PhaseHealth, code, err)
+}
+
+// failureRecord turns a processJob error into the structured record sent
+// with the FAILED status.
+func failureRecord(err error) *api.DeploymentFailure {
+	failure := &api.DeploymentFailure{
+		Phase:   api.PhaseSetup,
+		Code:    "UNKNOWN",
+		Message: err.Error(),
+	}
+
+	var jobErr *jobError
+	if errors.As(err, &jobErr) {
+		failure.Phase = jobErr.phase
+		failure.Code = jobErr.code
+	}
+
+	var cmdErr *types.CommandError
+	if errors.As(err, &cmdErr) {
+		if cmdErr.ExitCode >= 0 {
+			exitCode := cmdErr.ExitCode
+			failure.ExitCode = &exitCode
+		}
+		failure.LogTail = cmdErr.Output
+	}
+
+	var notReady *k8s.ReadinessError
+	if errors.As(err, &notReady) {
+		if notReady.ExitCode >= 0 {
+			exitCode := int(notReady.ExitCode)
+			failure.ExitCode = &exitCode
+		}
+		if notReady.TerminationMessage != "" {
+			failure.LogTail = strings.Split(notReady.TerminationMessage, "\n")
+		}
+	}
+
+	return failure
+}
+
+// jobPhase returns the phase a processJob error happened in.
+func jobPhase(err error) api.FailurePhase {
+	var jobErr *jobError
+	if errors.As(err, &jobErr) {
+		return jobErr.phase
+	}
+	return api.PhaseSetup
+}
+
+// logCancelled closes the build log of a cancelled deployment, saying which
+// phase the cancellation interrupted.
+func (w *Worker) logCancelled(deploymentID string, phase api.FailurePhase) {
+	buildLog := w.logFactory.CreateBuildLogger(deploymentID)
+	defer buildLog.Close()
+
+	buildLog.Log("")
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log("  🚫 Deployment Cancelled")
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log("  The deployment was cancelled by the user.")
+	buildLog.Log(fmt.Sprintf("  Stopped during: %s", phase))
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+}
+
+// logFailure closes the build log with a summary of why the deployment failed.
+func (w *Worker) logFailure(deploymentID string, failure *api.DeploymentFailure) {
+	buildLog := w.logFactory.CreateBuildLogger(deploymentID)
+	defer buildLog.Close()
+
+	buildLog.Log("")
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log("  ❌ Deployment Failed")
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log(fmt.Sprintf("  Phase:  %s", failure.Phase))
+	buildLog.Log(fmt.Sprintf("  Reason: %s", failure.Message))
+	if failure.ExitCode != nil {
+		buildLog.Log(fmt.Sprintf("  Exit:   %d", *failure.ExitCode))
+	}
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+}
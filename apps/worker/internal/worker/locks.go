@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"time"
+
+	"code2cloud/worker/internal/k8s"
+	"code2cloud/worker/internal/types"
+)
+
+// Jobs that deploy the same Kubernetes resources (one project, environment
+// and branch) run one at a time across every worker, under a lock in Redis
+// (see queue.TryLockProject). Two builds of one project would otherwise
+// race on the same Deployment/Service/Ingress names.
+
+// projectBusyDelay is how long a job waits in the delayed set before trying
+// its project's lock again.
+const projectBusyDelay = 5 * time.Second
+
+// projectLockKey names the resources a job deploys.
+func projectLockKey(job *types.BuildJob) string {
+	return k8s.ResourceName(job.ProjectName, job.JobEnvironment(), job.Branch)
+}
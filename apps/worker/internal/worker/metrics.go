@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/metrics"
+)
+
+const queueDepthInterval = 15 * time.Second
+
+// startQueueDepthSampler refreshes the queue depth gauges until ctx is
+// cancelled. Every worker samples the same keys, so any replica's series
+// can be used for alerting.
+func (w *Worker) startQueueDepthSampler(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(queueDepthInterval)
+		defer ticker.Stop()
+
+		for {
+			w.sampleQueueDepth(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (w *Worker) sampleQueueDepth(ctx context.Context) {
+	depths, err := w.queue.Depths(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Debug("Failed to sample queue depth", zap.Error(err))
+		}
+		return
+	}
+
+	for name, depth := range depths {
+		metrics.QueueDepth.WithLabelValues(name).Set(float64(depth))
+	}
+}
+
+// frameworkLabel keeps the build duration label set bounded.
+func frameworkLabel(framework string) string {
+	if framework == "" {
+		return "unknown"
+	}
+	return framework
+}
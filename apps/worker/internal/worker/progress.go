@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"code2cloud/worker/internal/health"
+)
+
+const (
+	// pollStallTimeout is how long an idle pool may go without hearing back
+	// from Redis before the worker is considered wedged. WaitForJob blocks
+	// for at most 5s per poll.
+	pollStallTimeout = 2 * time.Minute
+
+	// notReadyBackoff is how long a slot waits before re-checking readiness
+	notReadyBackoff = 5 * time.Second
+)
+
+// slotState tracks what one pool slot is doing so liveness can spot a
+// loop that stopped polling or a job that never finishes.
+type slotState struct {
+	busySince atomic.Int64 // unix nanos, 0 while idle
+}
+
+// newHealthChecker wires the worker's dependencies into readiness checks
+// and its own progress into liveness.
+func (w *Worker) newHealthChecker() *health.Checker {
+	return health.New(health.Config{
+		Logger: w.logger,
+		Liveness: map[string]health.CheckFunc{
+			"job_loop": w.checkProgress,
+		},
+		Readiness: map[string]health.CheckFunc{
+			"redis":      w.queue.Ping,
+			"api":        w.api.HealthCheck,
+			"kubernetes": w.k8s.Ping,
+			"builder":    w.builder.HealthCheck,
+		},
+		Interval: w.cfg.HealthCheckInterval,
+	})
+}
+
+// Health returns the checker backing the /livez and /readyz probes.
+func (w *Worker) Health() *health.Checker {
+	return w.health
+}
+
+// checkProgress fails when an idle pool has stopped polling the queue or a
+// slot has been stuck on one job far longer than a build can take.
+func (w *Worker) checkProgress(ctx context.Context) error {
+	// Slots exit on purpose while draining
+	if w.draining.Load() {
+		return nil
+	}
+
+	now := time.Now()
+	maxJob := w.jobBudget()
+	idle := 0
+
+	for i := range w.slots {
+		since := w.slots[i].busySince.Load()
+		if since == 0 {
+			idle++
+			continue
+		}
+		if running := now.Sub(time.Unix(0, since)); running > maxJob {
+			return fmt.Errorf("slot %d has been running one job for %s", i+1, running.Round(time.Second))
+		}
+	}
+
+	if idle == 0 {
+		return nil
+	}
+
+	last := w.queue.LastPoll()
+	if nanos := w.lastPause.Load(); nanos != 0 {
+		if paused := time.Unix(0, nanos); paused.After(last) {
+			last = paused
+		}
+	}
+	if last.IsZero() {
+		// Not started polling yet
+		return nil
+	}
+	if stalled := now.Sub(last); stalled > pollStallTimeout {
+		return fmt.Errorf("job loop has not polled the queue for %s", stalled.Round(time.Second))
+	}
+
+	return nil
+}
+
+// jobBudget is the longest a job may legitimately run once it holds its
+// project lock: a full build, every canary step, and slack for the clone,
+// push, rollout and health checks.
+func (w *Worker) jobBudget() time.Duration {
+	canary := time.Duration(len(w.cfg.CanarySteps)) * w.cfg.CanaryStepInterval
+	return w.cfg.BuildTimeout + canary + 15*time.Minute
+}
+
+// waitUntilReady holds a slot back from taking jobs while a dependency is
+// down. It reports false once ctx is cancelled.
+func (w *Worker) waitUntilReady(ctx context.Context) bool {
+	for !w.health.Ready() {
+		w.lastPause.Store(time.Now().UnixNano())
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(notReadyBackoff):
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code2cloud/worker/internal/api"
+	"code2cloud/worker/internal/builder"
+	"code2cloud/worker/internal/k8s"
+	"code2cloud/worker/internal/logging"
+	"code2cloud/worker/internal/types"
+)
+
+// deployOptions describes the Kubernetes resources for running image with
+// the job's runtime env vars and port.
+func (w *Worker) deployOptions(job *types.BuildJob, image string, settings *api.ProjectSettings) k8s.DeployOptions {
+	port := resolvePort(job.EnvVars)
+
+	runtimeEnvVars := builder.MergeEnvVars(
+		job.EnvVars,
+		builder.FrameworkRuntimePortEnv(job.BuildConfig.Framework, port),
+	)
+	runtimeEnvVars["PORT"] = fmt.Sprintf("%d", port)
+
+	opts := k8s.DeployOptions{
+		DeploymentID:  job.DeploymentID,
+		ProjectID:     job.ProjectID,
+		ProjectName:   job.ProjectName,
+		Environment:   job.JobEnvironment(),
+		Branch:        job.Branch,
+		Strategy:      job.Strategy,
+		ImageName:     image,
+		Port:          port,
+		CPURequest:    settings.DefaultCPURequest(),
+		CPULimit:      settings.DefaultCPULimit(),
+		MemoryRequest: settings.DefaultMemoryRequest(),
+		MemoryLimit:   settings.DefaultMemoryLimit(),
+		Replicas:      1,
+		EnvVars:       runtimeEnvVars,
+		Domains:       job.Domains,
+		BaseDomain:    w.cfg.BaseDomain,
+	}
+	if job.HealthCheck != nil {
+		opts.HealthCheck = *job.HealthCheck
+	}
+	if job.Scaling != nil {
+		opts.Scaling = *job.Scaling
+		if job.Scaling.MinReplicas > 0 {
+			opts.Replicas = job.Scaling.MinReplicas
+		}
+	}
+	return opts
+}
+
+// releaseBuild finishes a build-only job: the image is pushed, so the
+// deployment is marked BUILT with its image and digest and nothing is
+// deployed.
+func (w *Worker) releaseBuild(ctx context.Context, job *types.BuildJob, buildResult *builder.Result, startTime time.Time, timings *api.DeploymentTimings, buildLog *logging.StreamLogger) error {
+	duration := time.Since(startTime)
+	timings.TotalMs = millis(duration)
+
+	if err := w.api.CompleteBuild(ctx, job.DeploymentID, buildResult.ImageName, buildResult.Digest, timings); err != nil {
+		return phaseError(api.PhaseBuild, "STATUS_UPDATE_FAILED", fmt.Errorf("failed to complete build: %w", err))
+	}
+
+	buildLog.Log("")
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log("  📦 Build Complete (not deployed)")
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log(fmt.Sprintf("  Image:    %s", buildResult.ImageName))
+	if buildResult.Digest != "" {
+		buildLog.Log(fmt.Sprintf("  Digest:   %s", buildResult.Digest))
+	}
+	buildLog.Log(fmt.Sprintf("  Duration: %s", duration.Round(time.Second)))
+	buildLog.Log(fmt.Sprintf("  Phases:   %s", formatTimings(timings)))
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+
+	w.logger.Info("Build-only job completed",
+		zap.String("deployment", job.DeploymentID),
+		zap.String("image", buildResult.ImageName),
+		zap.String("digest", buildResult.Digest),
+		zap.Duration("duration", duration),
+	)
+
+	return nil
+}
+
+// release marks a deployment that is running in Kubernetes as READY,
+// retires the one it replaces, notifies the user and starts streaming
+// runtime logs. Shared by full and deploy-only jobs.
+func (w *Worker) release(ctx context.Context, job *types.BuildJob, deployResult *k8s.DeployResult, startTime time.Time, timings *api.DeploymentTimings, buildLog *logging.StreamLogger) error {
+	// ─────────────────────────────────────────────────────────
+	// Mark as READY + retire previous deployment
+	// ─────────────────────────────────────────────────────────
+	deploymentURL := deployResult.URLs[0]
+
+	duration := time.Since(startTime)
+	timings.TotalMs = millis(duration)
+
+	if err := w.api.CompleteDeployment(ctx, job.DeploymentID, deploymentURL, timings); err != nil {
+		return phaseError(api.PhaseDeploy, "STATUS_UPDATE_FAILED", fmt.Errorf("failed to complete deployment: %w", err))
+	}
+
+	if !job.IsPreview() {
+		w.api.UpdateProjectStatus(ctx, job.ProjectID, "ACTIVE")
+	}
+
+	if job.PreviousDeploymentID != "" {
+		w.logStreamer.StopStreaming(job.PreviousDeploymentID)
+		w.api.UpdateDeploymentStatus(ctx, job.PreviousDeploymentID, types.StatusSuperseded)
+	}
+
+	for _, domain := range job.Domains {
+		w.logger.Debug("Domain configured", zap.String("domain", domain))
+	}
+
+	title := "Deployment Complete!"
+	if job.IsRollback() {
+		title = "Rollback Complete!"
+	} else if job.IsPreview() {
+		title = "Preview Deployment Complete!"
+	}
+
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log(fmt.Sprintf("  ✅ %s", title))
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+	buildLog.Log(fmt.Sprintf("  URL:      %s", deploymentURL))
+	if len(deployResult.URLs) > 1 {
+		buildLog.Log(fmt.Sprintf("  Aliases:  %s", strings.Join(deployResult.URLs[1:], ", ")))
+	}
+	buildLog.Log(fmt.Sprintf("  Duration: %s", duration.Round(time.Second)))
+	buildLog.Log(fmt.Sprintf("  Phases:   %s", formatTimings(timings)))
+	buildLog.Log("═══════════════════════════════════════════════════════════")
+
+	// ─────────────────────────────────────────────────────────
+	// Send success notification
+	// ─────────────────────────────────────────────────────────
+	w.api.NotifySuccess(ctx, job.DeploymentID, job.ProjectName, deploymentURL, int(duration.Seconds()))
+
+	// ─────────────────────────────────────────────────────────
+	// Start streaming runtime logs
+	// ─────────────────────────────────────────────────────────
+	// Streams outlive this job's context; StopStreaming/StopAll end them
+	if err := w.logStreamer.StartStreaming(context.WithoutCancel(ctx), job.DeploymentID, deployResult.AppName); err != nil {
+		w.logger.Warn("Failed to start runtime log streaming (non-fatal)",
+			zap.String("deployment", job.DeploymentID),
+			zap.Error(err),
+		)
+		// Don't return error — deployment is already live
+	} else {
+		w.logger.Info("Runtime log streaming started",
+			zap.String("deployment", job.DeploymentID),
+			zap.Int("active_streams", w.logStreamer.ActiveStreams()),
+		)
+	}
+
+	w.logger.Info("Job completed successfully! 🎉",
+		zap.String("deployment", job.DeploymentID),
+		zap.String("type", job.JobType()),
+		zap.String("url", deploymentURL),
+		zap.Duration("duration", duration),
+		zap.Int64("queue_wait_ms", timings.QueueWaitMs),
+		zap.Int64("build_ms", timings.BuildMs),
+		zap.Int64("readiness_ms", timings.ReadinessMs),
+	)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"code2cloud/worker/internal/api"
+	"code2cloud/worker/internal/types"
+)
+
+// newTimings starts the timing breakdown for a job picked up at pickedUp.
+// Queue wait is only known when the API stamped the job with queuedAt.
+func newTimings(job *types.BuildJob, pickedUp time.Time) *api.DeploymentTimings {
+	timings := &api.DeploymentTimings{}
+
+	if job.QueuedAt == "" {
+		return timings
+	}
+
+	queuedAt, err := time.Parse(time.RFC3339Nano, job.QueuedAt)
+	if err == nil && pickedUp.After(queuedAt) {
+		timings.QueueWaitMs = millis(pickedUp.Sub(queuedAt))
+	}
+
+	return timings
+}
+
+func millis(d time.Duration) int64 {
+	return d.Milliseconds()
+}
+
+// formatTimings renders the phases that ran as a single build-log line,
+// e.g. "clone 3s · build 1m12s · deploy 9s".
+func formatTimings(t *api.DeploymentTimings) string {
+	phases := []struct {
+		name string
+		ms   int64
+	}{
+		{"queue", t.QueueWaitMs},
+		{"auth", t.TokenFetchMs},
+		{"clone", t.CloneMs},
+		{"plan", t.PrepareMs},
+		{"build", t.BuildMs},
+		{"apply", t.ApplyMs},
+		{"rollout", t.ReadinessMs},
+	}
+
+	parts := make([]string, 0, len(phases))
+	for _, p := range phases {
+		if p.ms <= 0 {
+			continue
+		}
+		d := time.Duration(p.ms) * time.Millisecond
+		if d >= time.Second {
+			d = d.Round(time.Second)
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", p.name, d))
+	}
+
+	return strings.Join(parts, " · ")
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -14,8 +16,10 @@ import (
 	"code2cloud/worker/internal/builder"
 	"code2cloud/worker/internal/config"
 	"code2cloud/worker/internal/git"
+	"code2cloud/worker/internal/health"
 	"code2cloud/worker/internal/k8s"
 	"code2cloud/worker/internal/logging"
+	"code2cloud/worker/internal/metrics"
 	"code2cloud/worker/internal/queue"
 	"code2cloud/worker/internal/types"
 )
@@ -37,6 +41,16 @@ type Worker struct {
 	cleanupWorker     *k8s.CleanupWorker
 	logCleanupWorker  *k8s.LogCleanupWorker
 	projectCleanupWorker *k8s.ProjectCleanupWorker
+	replicaReporter      *k8s.ReplicaReporter
+	activator            *k8s.Activator
+	reaper               *queue.Reaper
+
+	activeJobs   atomic.Int32
+
+	health    *health.Checker
+	slots     []slotState
+	draining  atomic.Bool
+	lastPause atomic.Int64
 }
 
 func New(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Worker, error) {
@@ -44,6 +58,7 @@ func New(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Worker,
 	q, err := queue.New(
 		cfg.RedisURL,
 		cfg.QueueName,
+		cfg.WorkerID,
 		logger,
 	)
 	if err != nil {
@@ -78,14 +93,25 @@ func New(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Worker,
 		Platform:         cfg.BuildPlatform,
 		Timeout:          cfg.BuildTimeout,
 	}
-	bldr := builder.NewBuilder(builderConfig, logFactory, logger)
+	backend, err := builder.NewBackend(cfg.BuildBackend, builderConfig, logger)
+	if err != nil {
+		q.Close()
+		return nil, fmt.Errorf("failed to create build backend: %w", err)
+	}
+	bldr := builder.NewBuilder(builderConfig, backend, logFactory, logger)
 
 	// ─────────────────────────────────────────────────────────
 	// Initialize Kubernetes Client
 	// ─────────────────────────────────────────────────────────
 	k8sClient, err := k8s.NewClient(k8s.Config{
-		Namespace:  cfg.Namespace,
-		BaseDomain: cfg.BaseDomain,
+		Namespace:            cfg.Namespace,
+		BaseDomain:           cfg.BaseDomain,
+		BlueGreenGracePeriod: cfg.BlueGreenGracePeriod,
+		Canary: k8s.CanaryConfig{
+			Steps:        cfg.CanarySteps,
+			StepInterval: cfg.CanaryStepInterval,
+			MaxErrorRate: cfg.CanaryMaxErrorRate,
+		},
 	}, logFactory, logger)
 	if err != nil {
 		q.Close()
@@ -93,6 +119,7 @@ func New(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Worker,
 	}
 
 	logStreamer := k8s.NewLogStreamer(k8sClient, logFactory, logger)
+	metrics.RegisterActiveStreams(logStreamer.ActiveStreams)
 
 	domainManager := k8s.NewDomainManager(k8sClient, k8s.DomainConfig{
 		ServerIP:   cfg.ServerIP,
@@ -129,6 +156,25 @@ func New(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Worker,
 		Logger:           logger,
 		FetchCleanupJobs: q.PopProjectCleanup,
 		CheckInterval:    5 * time.Second,
+
+		FetchPreviewCleanupJobs: q.PopPreviewCleanup,
+	})
+
+	replicaReporter := k8s.NewReplicaReporter(k8s.ReplicaReporterConfig{
+		Client:         k8sClient,
+		ReportReplicas: apiClient.ReportReplicas,
+		CheckInterval:  30 * time.Second,
+		Logger:         logger,
+	})
+
+	activator := k8s.NewActivator(k8s.ActivatorConfig{
+		Client:      k8sClient,
+		LogStreamer: logStreamer,
+		Logger:      logger,
+		MarkAwake: func(ctx context.Context, deploymentID string) error {
+			return apiClient.UpdateDeploymentStatus(ctx, deploymentID, types.StatusReady)
+		},
+		WakeTimeout: cfg.WakeTimeout,
 	})
 
 	// Create worker instance
@@ -146,15 +192,33 @@ func New(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Worker,
 		cleanupWorker:        cleanupWorker,
 		logCleanupWorker:     logCleanupWorker,
 		projectCleanupWorker: projectCleanupWorker,
+		replicaReporter:      replicaReporter,
+		activator:            activator,
+		slots:                make([]slotState, cfg.ConcurrentJobs),
 	}
 
+	w.health = w.newHealthChecker()
+
+	w.reaper = queue.NewReaper(queue.ReaperConfig{
+		Queue:         q,
+		Logger:        logger,
+		OnRequeue:     w.onJobRequeued,
+		CheckInterval: 15 * time.Second,
+	})
+
 	return w, nil
 }
 
+// Activator wakes sleeping apps; main serves it next to the health probes.
+func (w *Worker) Activator() *k8s.Activator {
+	return w.activator
+}
+
 func (w *Worker) Start(ctx context.Context) error {
 	w.logger.Info("Worker started, waiting for jobs...",
 		zap.String("queue", w.cfg.QueueName),
 		zap.String("worker_id", w.cfg.WorkerID),
+		zap.Int("concurrent_jobs", w.cfg.ConcurrentJobs),
 		zap.String("api_url", w.cfg.APIBaseURL),
 		zap.String("workspace", w.cfg.WorkspacePath),
 		zap.String("buildkit_addr", w.cfg.BuildkitAddr),
@@ -167,60 +231,217 @@ func (w *Worker) Start(ctx context.Context) error {
 	w.cleanupWorker.Start(ctx)
 	w.logCleanupWorker.Start(ctx)
 	w.projectCleanupWorker.Start(ctx)
+	w.replicaReporter.Start(ctx)
+	w.reaper.Start(ctx)
+
+	// In-flight jobs run on their own context so a shutdown signal stops
+	// pulling new work without killing builds that are already running.
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	// Readiness keeps reporting while jobs drain
+	w.health.Start(jobCtx)
+
+	// The heartbeat outlives ctx so other workers don't reclaim jobs
+	// that are still draining.
+	w.queue.StartHeartbeat(jobCtx)
+	w.queue.StartRetryPromoter(ctx)
+	w.startQueueDepthSampler(ctx)
+
+	var wg sync.WaitGroup
+	for slot := 1; slot <= w.cfg.ConcurrentJobs; slot++ {
+		wg.Add(1)
+		go func(slot int) {
+			defer wg.Done()
+			w.runLoop(ctx, jobCtx, slot)
+		}(slot)
+	}
+
+	<-ctx.Done()
+	w.draining.Store(true)
+
+	w.logger.Info("Shutting down worker, draining in-flight jobs...",
+		zap.Int32("active_jobs", w.activeJobs.Load()),
+		zap.Duration("timeout", w.cfg.ShutdownTimeout),
+	)
 
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		w.logger.Info("All in-flight jobs drained")
+	case <-time.After(w.cfg.ShutdownTimeout):
+		w.logger.Warn("Drain timeout reached, cancelling in-flight jobs",
+			zap.Int32("active_jobs", w.activeJobs.Load()),
+		)
+		cancelJobs()
+		<-drained
+	}
+
+	cancelJobs()
+	return w.shutdown()
+}
+
+// runLoop pulls jobs from the queue until ctx is cancelled. Each loop is one
+// slot of the pool; jobs themselves run on jobCtx so they can drain.
+func (w *Worker) runLoop(ctx, jobCtx context.Context, slot int) {
 	for {
-		select {
-		case <-ctx.Done():
-			w.logger.Info("Shutting down worker...")
-			return w.shutdown()
-		default:
-			// Continue processing
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !w.waitUntilReady(ctx) {
+			return
 		}
 
 		job, jobID, err := w.queue.WaitForJob(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
-				return w.shutdown()
+				return
+			}
+			w.logger.Error("Error waiting for job",
+				zap.Int("slot", slot),
+				zap.Error(err),
+			)
+
+			// Back off so a Redis outage doesn't turn into a hot loop
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
 			}
-			w.logger.Error("Error waiting for job", zap.Error(err))
 			continue
 		}
 
-		w.logger.Info("Processing job",
+		w.handleJob(jobCtx, job, jobID, slot)
+	}
+}
+
+// handleJob runs a job under its project lock and reports the outcome.
+func (w *Worker) handleJob(ctx context.Context, job *types.BuildJob, jobID string, slot int) {
+	pickedUp := time.Now()
+	timings := newTimings(job, pickedUp)
+
+	lockKey := projectLockKey(job)
+
+	locked, err := w.queue.TryLockProject(ctx, lockKey, jobID)
+	if err != nil {
+		w.logger.Warn("Failed to take project lock",
 			zap.String("jobId", jobID),
-			zap.String("deploymentId", job.DeploymentID),
 			zap.String("project", job.ProjectName),
+			zap.Error(err),
 		)
+	}
+	if !locked {
+		// Waiting here would hold a slot other projects could use; the
+		// job goes back to the queue and tries again shortly
+		w.deferJob(context.WithoutCancel(ctx), job, jobID)
+		return
+	}
+	defer w.queue.UnlockProject(context.WithoutCancel(ctx), lockKey)
 
-		if err := w.processJob(ctx, job, jobID); err != nil {
-			w.logger.Error("Job processing failed",
-				zap.String("jobId", jobID),
-				zap.Error(err),
-			)
+	// Liveness judges the job from here; waiting on another job of the
+	// project doesn't count against it
+	state := &w.slots[slot-1]
+	state.busySince.Store(time.Now().UnixNano())
+	defer state.busySince.Store(0)
 
-			if errors.Is(err, errCancelled) {
-				// Cancellation: status already set by the API, just log and clean up the signal
-				w.api.UpdateDeploymentStatus(ctx, job.DeploymentID, types.StatusCanceled)
-				w.api.NotifyFailure(ctx, job.DeploymentID, job.ProjectName, "Deployment cancelled by user")
-				w.cancelCleanup(ctx, job)
-				w.queue.ClearCancelSignal(ctx, job.DeploymentID)
-			} else {
-				w.api.FailDeployment(ctx, job.DeploymentID, err.Error())
-				w.api.NotifyFailure(ctx, job.DeploymentID, job.ProjectName, err.Error())
-			}
+	w.activeJobs.Add(1)
+	defer w.activeJobs.Add(-1)
+
+	w.logger.Info("Processing job",
+		zap.String("jobId", jobID),
+		zap.String("deploymentId", job.DeploymentID),
+		zap.String("project", job.ProjectName),
+		zap.String("type", job.JobType()),
+		zap.String("environment", job.JobEnvironment()),
+		zap.Int("slot", slot),
+	)
+
+	// A cancel signal cancels the job's context right away, killing any
+	// running git/railpack/buildctl process instead of waiting for the
+	// next checkpoint.
+	runCtx, cancelRun := context.WithCancelCause(ctx)
+	defer cancelRun(nil)
+
+	stopWatch := w.queue.WatchCancel(ctx, job.DeploymentID, func() {
+		w.logger.Info("Cancel signal received, stopping job",
+			zap.String("jobId", jobID),
+			zap.String("deployment", job.DeploymentID),
+		)
+		cancelRun(errCancelled)
+	})
 
-			w.queue.FailJob(ctx, jobID, err.Error())
+	err = w.processJob(runCtx, job, jobID, timings)
+	stopWatch()
+
+	if err != nil {
+		w.logger.Error("Job processing failed",
+			zap.String("jobId", jobID),
+			zap.Error(err),
+		)
+
+		// Report on a context that survives a drain timeout so the
+		// deployment doesn't stay BUILDING forever.
+		reportCtx := context.WithoutCancel(ctx)
+
+		cancelled := errors.Is(err, errCancelled) || errors.Is(context.Cause(runCtx), errCancelled)
+
+		if ctx.Err() != nil && !cancelled {
+			// Shutdown interrupted the job; let another worker retry it
 			w.logStreamer.StopStreaming(job.DeploymentID)
+			w.requeueJob(reportCtx, job, jobID)
+			return
+		}
 
-			continue
+		w.logStreamer.StopStreaming(job.DeploymentID)
+
+		if cancelled {
+			// Cancellation: status already set by the API, just log and clean up the signal
+			w.logCancelled(job.DeploymentID, jobPhase(err))
+			w.api.UpdateDeploymentStatus(reportCtx, job.DeploymentID, types.StatusCanceled)
+			w.api.NotifyFailure(reportCtx, job.DeploymentID, job.ProjectName, "Deployment cancelled by user")
+			w.cancelCleanup(reportCtx, job)
+			w.queue.ClearCancelSignal(reportCtx, job.DeploymentID)
+			w.queue.FailJob(reportCtx, jobID, errCancelled.Error())
+			metrics.JobsProcessed.WithLabelValues(metrics.JobCanceled).Inc()
+			return
+		}
+
+		transient := types.IsTransient(err)
+		if transient && job.Attempt+1 < w.cfg.MaxJobAttempts {
+			w.retryJob(reportCtx, job, jobID, err)
+			return
 		}
 
-		w.queue.CompleteJob(ctx, jobID)
+		timings.TotalMs = millis(time.Since(pickedUp))
+
+		failure := failureRecord(err)
+		w.logFailure(job.DeploymentID, failure)
+		w.api.FailDeployment(reportCtx, job.DeploymentID, failure, timings)
+		w.api.NotifyFailure(reportCtx, job.DeploymentID, job.ProjectName, err.Error())
+		w.queue.DeadLetterJob(reportCtx, job, jobID, err.Error(), transient)
+		metrics.JobsProcessed.WithLabelValues(metrics.JobFailed).Inc()
+		return
 	}
+
+	w.queue.CompleteJob(ctx, jobID)
+	metrics.JobsProcessed.WithLabelValues(metrics.JobReady).Inc()
 }
 
-// processJob handles a single build job
-func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID string) error {
+// processJob handles a single build job, recording how long each phase
+// takes into timings. Build-only jobs stop after pushing the image;
+// deploy-only jobs skip git and the builder.
+func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID string, timings *api.DeploymentTimings) error {
+	mode := job.JobMode()
+	if mode == types.ModeDeployOnly {
+		return w.processDeployOnly(ctx, job, timings)
+	}
+
 	startTime := time.Now()
 
 	// Create a build logger for this deployment
@@ -235,16 +456,19 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 	)
 
 	// ── Cancel check: job may have been cancelled while queued ──
-	if err := w.checkCancelled(ctx, job.DeploymentID, buildLog); err != nil {
+	if err := w.checkCancelled(ctx, job.DeploymentID, api.PhaseSetup); err != nil {
 		return err
 	}
 
 	// ─────────────────────────────────────────────────────────
 	// Step 1: Update deployment status to BUILDING
 	// ─────────────────────────────────────────────────────────
-	w.api.UpdateProjectStatus(ctx, job.ProjectID, "PENDING")
+	// Build-only jobs and previews never touch what's serving production
+	if mode != types.ModeBuildOnly && !job.IsPreview() {
+		w.api.UpdateProjectStatus(ctx, job.ProjectID, "PENDING")
+	}
 	if err := w.api.UpdateDeploymentStatus(ctx, job.DeploymentID, types.StatusBuilding); err != nil {
-		return fmt.Errorf("failed to update status to BUILDING: %w", err)
+		return phaseError(api.PhaseSetup, "STATUS_UPDATE_FAILED", fmt.Errorf("failed to update status to BUILDING: %w", err))
 	}
 
 	buildLog.Log("═══════════════════════════════════════════════════════════")
@@ -254,11 +478,20 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 	buildLog.Log(fmt.Sprintf("  Commit:    %s", job.CommitHash[:8]))
 	buildLog.Log(fmt.Sprintf("  Framework: %s", job.BuildConfig.Framework))
 	buildLog.Log(fmt.Sprintf("  Domains:   %v", job.Domains))
+	if mode == types.ModeBuildOnly {
+		buildLog.Log("  Mode:      build only (no deploy)")
+	}
+	if job.IsPreview() {
+		buildLog.Log("  Env:       preview")
+	}
+	if job.Attempt > 0 {
+		buildLog.Log(fmt.Sprintf("  Attempt:   %d of %d", job.Attempt+1, w.cfg.MaxJobAttempts))
+	}
 	buildLog.Log("═══════════════════════════════════════════════════════════")
 	buildLog.Log("")
 
 	// ── Cancel check: before fetching settings ──
-	if err := w.checkCancelled(ctx, job.DeploymentID, buildLog); err != nil {
+	if err := w.checkCancelled(ctx, job.DeploymentID, api.PhaseSetup); err != nil {
 		return err
 	}
 
@@ -282,9 +515,11 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 	buildLog.Log("─────────────────────────────────────────────────────────────")
 	buildLog.Log("🔑 Authenticating with GitHub...")
 
+	tokenStart := time.Now()
 	token, err := w.api.GetInstallationToken(ctx, job.InstallationID)
+	timings.TokenFetchMs = millis(time.Since(tokenStart))
 	if err != nil {
-		return fmt.Errorf("failed to get installation token: %w", err)
+		return phaseError(api.PhaseClone, "GIT_AUTH_FAILED", fmt.Errorf("failed to get installation token: %w", err))
 	}
 
 	w.logger.Info("Got installation token",
@@ -292,7 +527,7 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 	)
 
 	// ── Cancel check: before cloning ──
-	if err := w.checkCancelled(ctx, job.DeploymentID, buildLog); err != nil {
+	if err := w.checkCancelled(ctx, job.DeploymentID, api.PhaseClone); err != nil {
 		return err
 	}
 
@@ -313,11 +548,14 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 		Depth:          1,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+		return phaseError(api.PhaseClone, "CLONE_FAILED", fmt.Errorf("failed to clone repository: %w", err))
 	}
 
 	defer w.git.Cleanup(cloneResult.Path)
 
+	timings.CloneMs = millis(cloneResult.Duration)
+	metrics.CloneDuration.Observe(cloneResult.Duration.Seconds())
+
 	w.logger.Info("Repository cloned",
 		zap.String("path", cloneResult.Path),
 		zap.String("commit", cloneResult.CommitHash),
@@ -334,13 +572,13 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 	buildLog.Log("")
 
 	// ── Cancel check: before building ──
-	if err := w.checkCancelled(ctx, job.DeploymentID, buildLog); err != nil {
+	if err := w.checkCancelled(ctx, job.DeploymentID, api.PhaseBuild); err != nil {
 		w.git.Cleanup(cloneResult.Path)
 		return err
 	}
 
 	// ─────────────────────────────────────────────────────────
-	// Step 5: Build with Railpack + BuildKit
+	// Step 5: Build with BuildKit (Dockerfile or Railpack)
 	// ─────────────────────────────────────────────────────────
 	buildLog.Log("🔨 Phase 2: Build Image")
 	buildLog.Log("─────────────────────────────────────────────────────────────")
@@ -351,16 +589,22 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 		cloneResult.CommitHash[:8],
 	)
 
+	// Runtime-only vars (job.EnvVars) stay out of the build entirely
+	port := resolvePort(job.EnvVars)
+
 	envVars := builder.MergeEnvVars(
 		builder.DefaultBuildEnv(),
 		builder.FrameworkEnv(job.BuildConfig.Framework),
-		job.EnvVars,
 	)
-
-	port := resolvePort(job.EnvVars)
+	envVars["PORT"] = fmt.Sprintf("%d", port)
 
 	runCmd := builder.FrameworkStartCommand(job.BuildConfig.Framework, job.BuildConfig.RunCommand, port)
 
+	credentials, err := w.api.GetRegistryCredentials(ctx, job.ProjectID)
+	if err != nil {
+		return phaseError(api.PhaseBuild, "REGISTRY_CREDENTIALS_FAILED", err)
+	}
+
 	buildResult, err := w.builder.Build(ctx, builder.Options{
 		SourcePath:   sourcePath,
 		ImageName:    imageName,
@@ -372,27 +616,41 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 			RunCommand:     runCmd,
 			OutputDir:      job.BuildConfig.OutputDir,
 			Framework:      job.BuildConfig.Framework,
+			DockerfilePath: job.BuildConfig.Dockerfile,
+			DockerTarget:   job.BuildConfig.DockerTarget,
 		},
-		EnvVars: envVars,
+		EnvVars:             envVars,
+		BuildEnvVars:        job.BuildEnvVars,
+		BuildSecrets:        job.BuildSecrets,
+		RegistryCredentials: credentials,
 	})
 	if err != nil {
-		return fmt.Errorf("build failed: %w", err)
+		return phaseError(api.PhaseBuild, buildFailureCode(err), fmt.Errorf("build failed: %w", err))
 	}
 
+	timings.PrepareMs = millis(buildResult.PrepareDuration)
+	timings.BuildMs = millis(buildResult.Duration - buildResult.PrepareDuration)
+	metrics.BuildDuration.WithLabelValues(frameworkLabel(job.BuildConfig.Framework)).Observe(buildResult.Duration.Seconds())
+
 	w.logger.Info("Build completed",
 		zap.String("image", buildResult.ImageName),
+		zap.String("digest", buildResult.Digest),
 		zap.Duration("duration", buildResult.Duration),
 	)
 
+	if mode == types.ModeBuildOnly {
+		return w.releaseBuild(ctx, job, buildResult, startTime, timings, buildLog)
+	}
+
 	// Update deployment with image name
-	if err := w.api.UpdateDeploymentWithImage(ctx, job.DeploymentID, types.StatusBuilding, buildResult.ImageName); err != nil {
+	if err := w.api.UpdateDeploymentWithImage(ctx, job.DeploymentID, types.StatusBuilding, buildResult.ImageName, buildResult.Digest); err != nil {
 		w.logger.Warn("Failed to update deployment image", zap.Error(err))
 	}
 
 	buildLog.Log("")
 
 	// ── Cancel check: before deploying to Kubernetes ──
-	if err := w.checkCancelled(ctx, job.DeploymentID, buildLog); err != nil {
+	if err := w.checkCancelled(ctx, job.DeploymentID, api.PhaseDeploy); err != nil {
 		return err
 	}
 
@@ -400,7 +658,7 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 	// Step 6: Update status to DEPLOYING
 	// ─────────────────────────────────────────────────────────
 	if err := w.api.UpdateDeploymentStatus(ctx, job.DeploymentID, types.StatusDeploying); err != nil {
-		return fmt.Errorf("failed to update status to DEPLOYING: %w", err)
+		return phaseError(api.PhaseDeploy, "STATUS_UPDATE_FAILED", fmt.Errorf("failed to update status to DEPLOYING: %w", err))
 	}
 
 	buildLog.Log("🚢 Phase 3: Deploy to Kubernetes")
@@ -410,98 +668,128 @@ func (w *Worker) processJob(ctx context.Context, job *types.BuildJob, jobID stri
 	// Step 7: Deploy to Kubernetes
 	// ─────────────────────────────────────────────────────────
 
-	runtimeEnvVars := builder.MergeEnvVars(
-		job.EnvVars,
-		builder.FrameworkRuntimePortEnv(job.BuildConfig.Framework, port),
-	)
-	runtimeEnvVars["PORT"] = fmt.Sprintf("%d", port)
-
 	buildLog.Log(fmt.Sprintf("Container port: %d", port))
 
-	deployOpts := k8s.DeployOptions{
-		DeploymentID:  job.DeploymentID,
-		ProjectID:     job.ProjectID,
-		ProjectName:   job.ProjectName,
-		ImageName:     buildResult.ImageName,
-		Port:          port,
-		CPURequest:    settings.DefaultCPURequest(),
-		CPULimit:      settings.DefaultCPULimit(),
-		MemoryRequest: settings.DefaultMemoryRequest(),
-		MemoryLimit:   settings.DefaultMemoryLimit(),
-		Replicas:      1,
-		EnvVars:       runtimeEnvVars,
-		Domains:       job.Domains,
-		BaseDomain:    w.cfg.BaseDomain,
-		HealthPath:    "/health",
-	}
+	deployOpts := w.deployOptions(job, buildResult.ImageRef(), settings)
+
+	// Events cover the rollout and keep flowing while the deployment is
+	// live; StopStreaming ends them with the runtime logs
+	w.logStreamer.WatchEvents(context.WithoutCancel(ctx), job.DeploymentID, deployOpts.ResourceName())
 
 	deployResult, err := w.k8s.Deploy(ctx, deployOpts)
 	if err != nil {
-		return fmt.Errorf("kubernetes deployment failed: %w", err)
+		return deployError(err)
 	}
 
+	timings.ApplyMs = millis(deployResult.ApplyDuration)
+	timings.ReadinessMs = millis(deployResult.ReadyDuration)
+
 	buildLog.Log("")
 
-	// ─────────────────────────────────────────────────────────
-	// Step 8: Mark as READY + retire previous deployment
-	// ─────────────────────────────────────────────────────────
-	deploymentURL := deployResult.URLs[0]
+	return w.release(ctx, job, deployResult, startTime, timings, buildLog)
+}
 
-	if err := w.api.UpdateDeploymentWithURL(ctx, job.DeploymentID, types.StatusReady, deploymentURL); err != nil {
-		return fmt.Errorf("failed to complete deployment: %w", err)
+// retryJob schedules another attempt of a transiently failed job with
+// exponential backoff.
+func (w *Worker) retryJob(ctx context.Context, job *types.BuildJob, jobID string, cause error) {
+	delay := retryDelay(w.cfg.RetryBaseDelay, job.Attempt)
+
+	job.Attempt++
+	job.LastError = cause.Error()
+
+	// The next attempt's queue wait starts once the retry is due, not at
+	// the original enqueue, so earlier attempts and the backoff don't
+	// count as waiting
+	job.QueuedAt = time.Now().Add(delay).UTC().Format(time.RFC3339Nano)
+
+	buildLog := w.logFactory.CreateBuildLogger(job.DeploymentID)
+	buildLog.Log("")
+	buildLog.Log(fmt.Sprintf("⚠ Transient failure: %s", cause.Error()))
+	buildLog.Log(fmt.Sprintf("↻ Retrying in %s (attempt %d of %d)", delay, job.Attempt+1, w.cfg.MaxJobAttempts))
+	buildLog.Close()
+
+	if err := w.queue.RetryJob(ctx, job, jobID, delay); err != nil {
+		w.logger.Error("Failed to schedule retry, dead-lettering job",
+			zap.String("jobId", jobID),
+			zap.Error(err),
+		)
+		failure := failureRecord(cause)
+		w.logFailure(job.DeploymentID, failure)
+		w.api.FailDeployment(ctx, job.DeploymentID, failure, nil)
+		w.api.NotifyFailure(ctx, job.DeploymentID, job.ProjectName, cause.Error())
+		w.queue.DeadLetterJob(ctx, job, jobID, cause.Error(), true)
+		metrics.JobsProcessed.WithLabelValues(metrics.JobFailed).Inc()
+		return
 	}
 
-	w.api.UpdateProjectStatus(ctx, job.ProjectID, "ACTIVE")
+	metrics.JobsProcessed.WithLabelValues(metrics.JobRetried).Inc()
+	w.api.UpdateDeploymentStatus(ctx, job.DeploymentID, types.StatusQueued)
+}
+
+// retryDelay doubles base for every previous attempt, capped at 5 minutes.
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	const maxDelay = 5 * time.Minute
 
-	if job.PreviousDeploymentID != "" {
-		w.logStreamer.StopStreaming(job.PreviousDeploymentID)
-		w.api.UpdateDeploymentStatus(ctx, job.PreviousDeploymentID, types.StatusSuperseded)
+	delay := base
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
 	}
+	return delay
+}
 
-	for _, domain := range job.Domains {
-		w.logger.Debug("Domain configured", zap.String("domain", domain))
+// requeueJob hands an interrupted job back to the queue.
+func (w *Worker) requeueJob(ctx context.Context, job *types.BuildJob, jobID string) {
+	if err := w.queue.RequeueJob(ctx, jobID); err != nil {
+		w.logger.Error("Failed to requeue job, reaper will reclaim it",
+			zap.String("jobId", jobID),
+			zap.Error(err),
+		)
+		return
 	}
+	metrics.JobsProcessed.WithLabelValues(metrics.JobRequeued).Inc()
+	w.onJobRequeued(ctx, job, w.cfg.WorkerID)
+}
 
-	duration := time.Since(startTime)
+// deferJob puts a job whose project is busy with another job back on the
+// queue after projectBusyDelay.
+func (w *Worker) deferJob(ctx context.Context, job *types.BuildJob, jobID string) {
+	w.logger.Info("Project busy, deferring job",
+		zap.String("jobId", jobID),
+		zap.String("project", job.ProjectName),
+		zap.Duration("delay", projectBusyDelay),
+	)
 
-	buildLog.Log("═══════════════════════════════════════════════════════════")
-	buildLog.Log("  ✅ Deployment Complete!")
-	buildLog.Log("═══════════════════════════════════════════════════════════")
-	buildLog.Log(fmt.Sprintf("  URL:      %s", deploymentURL))
-	if len(deployResult.URLs) > 1 {
-		buildLog.Log(fmt.Sprintf("  Aliases:  %s", strings.Join(deployResult.URLs[1:], ", ")))
+	if err := w.queue.DeferJob(ctx, job, jobID, projectBusyDelay); err != nil {
+		w.logger.Error("Failed to defer job, requeueing it",
+			zap.String("jobId", jobID),
+			zap.Error(err),
+		)
+		if err := w.queue.RequeueJob(ctx, jobID); err != nil {
+			w.logger.Error("Failed to requeue job",
+				zap.String("jobId", jobID),
+				zap.Error(err),
+			)
+		}
 	}
-	buildLog.Log(fmt.Sprintf("  Duration: %s", duration.Round(time.Second)))
-	buildLog.Log("═══════════════════════════════════════════════════════════")
+}
 
-	// ─────────────────────────────────────────────────────────
-	// Step 9: Send success notification
-	// ─────────────────────────────────────────────────────────
-	w.api.NotifySuccess(ctx, job.DeploymentID, job.ProjectName, deploymentURL, int(duration.Seconds()))
+// onJobRequeued puts a requeued deployment back into QUEUED and tells the
+// user why their build restarted.
+func (w *Worker) onJobRequeued(ctx context.Context, job *types.BuildJob, workerID string) {
+	buildLog := w.logFactory.CreateBuildLogger(job.DeploymentID)
+	buildLog.Log("")
+	buildLog.Log(fmt.Sprintf("⚠ Worker %s stopped before finishing this deployment — it has been requeued", workerID))
+	buildLog.Close()
 
-	// ─────────────────────────────────────────────────────────
-	// Step 10: Start streaming runtime logs
-	// ─────────────────────────────────────────────────────────
-	if err := w.logStreamer.StartStreaming(ctx, job.DeploymentID, job.ProjectName); err != nil {
-		w.logger.Warn("Failed to start runtime log streaming (non-fatal)",
+	if err := w.api.UpdateDeploymentStatus(ctx, job.DeploymentID, types.StatusQueued); err != nil {
+		w.logger.Warn("Failed to mark requeued deployment as QUEUED",
 			zap.String("deployment", job.DeploymentID),
 			zap.Error(err),
 		)
-		// Don't return error — deployment is already live
-	} else {
-		w.logger.Info("Runtime log streaming started",
-			zap.String("deployment", job.DeploymentID),
-			zap.Int("active_streams", w.logStreamer.ActiveStreams()),
-		)
 	}
-
-	w.logger.Info("Job completed successfully! 🎉",
-		zap.String("deployment", job.DeploymentID),
-		zap.String("url", deploymentURL),
-		zap.Duration("duration", duration),
-	)
-
-	return nil
 }
 
 // shutdown cleans up resources
@@ -516,26 +804,24 @@ func (w *Worker) shutdown() error {
 	}
 	
 	if w.queue != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		w.queue.Deregister(ctx)
+		cancel()
 		w.queue.Close()
 	}
 	
 	return nil
 }
 
-// checkCancelled polls Redis to see if the deployment has been cancelled by the user.
-func (w *Worker) checkCancelled(ctx context.Context, deploymentID string, buildLog interface{ Log(string) }) error {
+// checkCancelled polls Redis between phases as a fallback for a missed
+// pub/sub signal (e.g. a job cancelled while it was still queued).
+func (w *Worker) checkCancelled(ctx context.Context, deploymentID string, phase api.FailurePhase) error {
 	if w.queue.IsCancelled(ctx, deploymentID) {
 		w.logger.Info("Deployment cancelled by user",
 			zap.String("deployment", deploymentID),
+			zap.String("phase", string(phase)),
 		)
-		buildLog.Log("")
-		buildLog.Log("═══════════════════════════════════════════════════════════")
-		buildLog.Log("  🚫 Deployment Cancelled")
-		buildLog.Log("═══════════════════════════════════════════════════════════")
-		buildLog.Log("  The deployment was cancelled by the user.")
-		buildLog.Log("═══════════════════════════════════════════════════════════")
-
-		return errCancelled
+		return phaseError(phase, "CANCELLED", errCancelled)
 	}
 	return nil
 }
@@ -551,6 +837,8 @@ func (w *Worker) cancelCleanup(ctx context.Context, job *types.BuildJob) {
 	cleanupErr := w.k8s.Cleanup(ctx, k8s.CleanupOptions{
 		DeploymentID: job.DeploymentID,
 		ProjectName:  job.ProjectName,
+		Environment:  job.JobEnvironment(),
+		Branch:       job.Branch,
 	})
 	if cleanupErr != nil {
 		w.logger.Warn("K8s cleanup on cancel (may be expected if resources were not yet created)",
@@ -562,7 +850,9 @@ func (w *Worker) cancelCleanup(ctx context.Context, job *types.BuildJob) {
 	// Notify the API to mark resources as cleaned up
 	w.api.CleanupDeployment(ctx, job.DeploymentID)
 
-	w.api.UpdateProjectStatus(ctx, job.ProjectID, "INACTIVE")
+	if !job.IsPreview() {
+		w.api.UpdateProjectStatus(ctx, job.ProjectID, "INACTIVE")
+	}
 }
 
 func resolvePort(envVars map[string]string) int32 {